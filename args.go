@@ -0,0 +1,220 @@
+package bindflags
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+// ArgTagName is the tag key binding a struct field to a positional argument, such as `arg:"0;src;required"`
+var ArgTagName = "arg"
+
+// argNames are the keys of the arg tag, in the order of the values without a key; validation rules are accepted too
+var argNames = []string{"index", "name"}
+
+// argField is a struct field bound to the positional argument index, or to the remaining arguments when rest is set
+type argField struct {
+	index    int
+	rest     bool
+	name     string
+	required bool
+	value    reflect.Value
+}
+
+func isArgField(ft reflect.StructField) bool {
+	_, ok := ft.Tag.Lookup(ArgTagName)
+	return ok
+}
+
+// scanArgFields returns the fields with an arg tag of the struct rv and of its groups, a rest field must be a slice
+func scanArgFields(rv reflect.Value) ([]*argField, error) {
+	rt := rv.Type()
+	var fields []*argField
+	for i := 0; i < rt.NumField(); i++ {
+		ft := rt.Field(i)
+		if !ft.IsExported() || ft.Tag.Get(TagName) == "-" || isCommandField(ft) {
+			continue
+		}
+		if !isArgField(ft) {
+			// the groups are allocated like bindField does, so that their arg fields are filled too
+			fv := rv.Field(i)
+			if fv.Kind() == reflect.Ptr && isGroupType(fv.Type().Elem()) {
+				if fv.IsNil() {
+					fv.Set(reflect.New(fv.Type().Elem()))
+				}
+				fv = fv.Elem()
+			}
+			if !isGroupType(fv.Type()) {
+				continue
+			}
+			sub, err := scanArgFields(fv)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, sub...)
+			continue
+		}
+		tag := ft.Tag.Get(ArgTagName)
+		kv, err := scanTag(tag, argNames)
+		if err != nil {
+			return nil, &TagError{Struct: rt, Field: ft.Name, Tag: tag, Err: err}
+		}
+		field := &argField{name: kv["name"], value: rv.Field(i)}
+		if v, ok := kv["required"]; ok {
+			if field.required, err = strconv.ParseBool(v); err != nil {
+				return nil, &TagError{Struct: rt, Field: ft.Name, Tag: tag, Err: fmt.Errorf("invalid required %q: %v", v, err)}
+			}
+		}
+		if kv["index"] == "rest" {
+			field.rest = true
+			if ft.Type.Kind() != reflect.Slice {
+				return nil, &TagError{Struct: rt, Field: ft.Name, Tag: tag, Err: errors.New("rest must be a slice")}
+			}
+		} else if field.index, err = strconv.Atoi(kv["index"]); err != nil || field.index < 0 {
+			return nil, &TagError{Struct: rt, Field: ft.Name, Tag: tag, Err: fmt.Errorf("invalid index %q", kv["index"])}
+		}
+		if field.name == "" {
+			field.name = "arg " + kv["index"]
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// argsRange returns the minimum and maximum number of arguments accepted by fields, most is -1 when there is a rest field
+func argsRange(fields []*argField) (least, most int) {
+	for _, field := range fields {
+		if field.rest {
+			most = -1
+			continue
+		}
+		if most >= 0 && field.index+1 > most {
+			most = field.index + 1
+		}
+		if field.required && field.index+1 > least {
+			least = field.index + 1
+		}
+	}
+	for _, field := range fields {
+		if field.rest && field.required {
+			least = restStart(fields) + 1
+		}
+	}
+	return least, most
+}
+
+// restStart returns the index of the first argument of the rest field
+func restStart(fields []*argField) int {
+	start := 0
+	for _, field := range fields {
+		if !field.rest && field.index+1 > start {
+			start = field.index + 1
+		}
+	}
+	return start
+}
+
+// BindArgs fills the fields of the struct a with an arg tag from the positional arguments left by FlagSet.Parse,
+// use it with FlagSet.Args(). `arg:"0"` is the first argument, `arg:"rest"` is a slice receiving every argument
+// after the indexed ones. The values are converted like the value of a flag tag, and the validation rules of
+// the tag are checked by Validate; a missing required argument or an unexpected one is an error.
+// BindArgs 用 FlagSet.Parse 之后剩余的位置参数填充结构体 a 中带 arg tag 的字段，如 `arg:"0;src;required"`、`arg:"rest"`
+func BindArgs(args []string, a any) error {
+	rv := reflect.ValueOf(a)
+	if rv.Kind() != reflect.Ptr {
+		return errors.New("a must be a pointer")
+	}
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		return errors.New("a must be a struct")
+	}
+	fields, err := scanArgFields(rv)
+	if err != nil || len(fields) == 0 {
+		return err
+	}
+	if err = checkArgsCount(fields, len(args)); err != nil {
+		return err
+	}
+	for _, field := range fields {
+		if field.rest {
+			start := restStart(fields)
+			if start > len(args) {
+				start = len(args)
+			}
+			items := args[start:]
+			slice := reflect.MakeSlice(field.value.Type(), len(items), len(items))
+			for i, item := range items {
+				if err = setArg(slice.Index(i), field.name, item); err != nil {
+					return err
+				}
+			}
+			field.value.Set(slice)
+		} else if field.index < len(args) {
+			if err = setArg(field.value, field.name, args[field.index]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func checkArgsCount(fields []*argField, n int) error {
+	least, most := argsRange(fields)
+	if n < least {
+		for _, field := range fields {
+			if field.required && (field.rest || field.index >= n) {
+				return &ValidationError{Flag: field.name, Rule: "required", Err: errors.New("argument is missing")}
+			}
+		}
+	}
+	if most >= 0 && n > most {
+		return fmt.Errorf("accepts at most %d arg(s), received %d", most, n)
+	}
+	return nil
+}
+
+func setArg(v reflect.Value, name, s string) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	x, err := convertTo(v.Type(), s)
+	if err != nil {
+		return fmt.Errorf("invalid argument %q for %s: %v", s, name, err)
+	}
+	v.Set(x)
+	return nil
+}
+
+// PositionalArgs returns a cobra.PositionalArgs checking the number of arguments against the arg fields of the struct a
+// PositionalArgs 根据结构体 a 的 arg 字段生成 cobra.PositionalArgs
+func PositionalArgs(a any) (cobra.PositionalArgs, error) {
+	rv := reflect.ValueOf(a)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("a must be a pointer to a struct")
+	}
+	fields, err := scanArgFields(rv.Elem())
+	if err != nil {
+		return nil, err
+	}
+	return func(cmd *cobra.Command, args []string) error {
+		return checkArgsCount(fields, len(args))
+	}, nil
+}
+
+// bindCommandArgs sets the Args of cmd from the arg fields of the struct a, unless cmd already has one or a has none
+func bindCommandArgs(cmd *cobra.Command, a any) error {
+	fields, err := scanArgFields(reflect.ValueOf(a).Elem())
+	if err != nil || len(fields) == 0 || cmd.Args != nil {
+		return err
+	}
+	cmd.Args = func(cmd *cobra.Command, args []string) error {
+		return checkArgsCount(fields, len(args))
+	}
+	return nil
+}
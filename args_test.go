@@ -0,0 +1,114 @@
+package bindflags
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type copyArgs struct {
+	Force bool     `flag:"name:force;shorthand:f"`
+	Src   string   `arg:"0;src;required"`
+	Dst   string   `arg:"1;dst;required;pattern:^/"`
+	Rest  []int    `arg:"rest;name:sizes;max:2"`
+	Mode  *string  `arg:"index:2"`
+	Extra []string `flag:"name:extra"`
+}
+
+func TestBindArgs(t *testing.T) {
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	c := new(copyArgs)
+	MustBindPFlags(f, c)
+	if f.Lookup("src") != nil {
+		t.Fatal("arg fields should not be bound to flags")
+	}
+	if err := f.Parse([]string{"-f", "a.txt", "/tmp", "0644", "1", "2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := BindArgs(f.Args(), c); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Force || c.Src != "a.txt" || c.Dst != "/tmp" || c.Mode == nil || *c.Mode != "0644" || len(c.Rest) != 2 || c.Rest[1] != 2 {
+		t.Fatalf("unexpected values: %#v", c)
+	}
+	if err := Validate(c); err != nil {
+		t.Fatal(err)
+	}
+
+	var ve *ValidationError
+	if err := BindArgs([]string{"a.txt"}, new(copyArgs)); !errors.As(err, &ve) || ve.Flag != "dst" {
+		t.Fatalf("expected dst to be required, got %v", err)
+	}
+	if err := BindArgs([]string{"a", "b", "c", "x"}, new(copyArgs)); err == nil {
+		t.Fatal("expected a conversion error")
+	}
+	c = new(copyArgs)
+	if err := BindArgs([]string{"a", "tmp", "m", "1", "2", "3"}, c); err != nil {
+		t.Fatal(err)
+	}
+	var errs ValidationErrors
+	if err := Validate(c); !errors.As(err, &errs) || len(errs) != 2 || errs[0].Rule != "pattern:^/" || errs[1].Rule != "max:2" {
+		t.Fatalf("expected the pattern and max errors, got %v", err)
+	}
+}
+
+func TestBindCommandArgs(t *testing.T) {
+	c := &struct {
+		Src string `arg:"0;src;required"`
+		Dst string `arg:"1;dst"`
+	}{}
+	cmd := &cobra.Command{Use: "copy", RunE: func(cmd *cobra.Command, args []string) error { return nil }}
+	cmd.SilenceErrors, cmd.SilenceUsage = true, true
+	MustBindCommand(cmd, c)
+	cmd.SetArgs([]string{"a", "b"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Src != "a" || c.Dst != "b" {
+		t.Fatalf("unexpected values: %#v", c)
+	}
+	cmd.SetArgs([]string{"a", "b", "c"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected the Args of the command to reject a third argument")
+	}
+	cmd.SetArgs(nil)
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected the Args of the command to require src")
+	}
+}
+
+func TestBindArgsRules(t *testing.T) {
+	c := &struct {
+		Src string `arg:"0;required"`
+		Dst struct {
+			Path string `arg:"1;required:1"`
+		}
+	}{}
+	var ve *ValidationError
+	if err := BindArgs(nil, c); !errors.As(err, &ve) || ve.Flag != "arg 0" {
+		t.Fatalf("expected the first argument to be required, got %v", err)
+	}
+	args, err := PositionalArgs(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = args(nil, []string{"a"}); !errors.As(err, &ve) || ve.Flag != "arg 1" {
+		t.Fatalf("expected the second argument to be required, got %v", err)
+	}
+	if err = BindArgs([]string{"a", "b"}, c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Src != "a" || c.Dst.Path != "b" {
+		t.Fatalf("unexpected values: %#v", c)
+	}
+	if err = Validate(c); err != nil {
+		t.Fatal(err)
+	}
+	if err = BindArgs(nil, &struct {
+		Src string `arg:"0;required:maybe"`
+	}{}); err == nil {
+		t.Fatal("expected an invalid required error")
+	}
+}
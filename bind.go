@@ -0,0 +1,237 @@
+package bindflags
+
+import (
+	"errors"
+	"flag"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Registrar registers the flags found by a Binder with a flag library. BindPFlags and BindFlags use one
+// for pflag and one for the flag package, Bind accepts an implementation for any other library.
+// Registrar 把 Binder 找到的标志注册到某个标志库，实现它即可用 Bind 接入 pflag 与 flag 以外的库
+type Registrar interface {
+	// Shorthands reports whether the library has one-letter shorthands, it sets the order of the tag values without a key:
+	// name;shorthand;value;usage;env with shorthands, name;value;usage;env without
+	Shorthands() bool
+	// Lookup returns the value of the flag registered with the name, nil when there is none
+	Lookup(name string) flag.Value
+	// LookupShorthand returns the name and the value of the flag registered with the shorthand, a nil value when there is none
+	LookupShorthand(shorthand string) (string, flag.Value)
+	// Register registers f and returns the flag.Value the library sets, which is f.Value when the library uses it as is
+	Register(f *Flag) (flag.Value, error)
+	// Changed reports whether the flag with the name was given on the command line
+	Changed(name string) bool
+}
+
+// Flag is a struct field to register as a flag, given to Registrar.Register
+type Flag struct {
+	// Name is the dotted name of the flag, such as db.host
+	Name string
+	// Shorthand is the one-letter shorthand, empty when there is none
+	Shorthand string
+	// Usage is the usage text, followed by the environment variable when there is one
+	Usage string
+	// Tag is the tag of the field, with its options such as Persistent, Encoding or Layout
+	Tag *PFlagTag
+	// Field is the addressable field, it holds the default value
+	Field reflect.Value
+	// Value sets Field from a command-line value, its String is the default value
+	Value pflag.Value
+	// native is set when Value was made by bindflags for a type the library may have its own flag for
+	native bool
+}
+
+// Bind binds the struct a to the flags of r, the same way BindPFlags binds it to a pflag.FlagSet
+// Bind 把结构体 a 绑定到 r 所代表的标志库
+func Bind(r Registrar, a any, group ...string) error {
+	return new(Binder).Bind(r, a, group...)
+}
+
+// Bind is like the package level Bind, using the options of the Binder
+func (b *Binder) Bind(r Registrar, a any, group ...string) error {
+	rv := reflect.ValueOf(a)
+	if rv.Kind() != reflect.Ptr {
+		return errors.New("a must be a pointer")
+	}
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		return errors.New("a must be a struct")
+	}
+	if err := b.addConfigFlag(r); err != nil {
+		return err
+	}
+	return b.bindStruct(r, rv, group, false)
+}
+
+// bindStruct binds the fields of the struct rv, persistent is set inside a struct marked persistent
+func (b *Binder) bindStruct(r Registrar, rv reflect.Value, group []string, persistent bool) error {
+	var errs BindErrors
+	for i := 0; i < rv.NumField(); i++ {
+		if err := b.bindField(r, rv, i, group, persistent); err != nil {
+			if !b.CollectErrors {
+				return err
+			}
+			errs = errs.append(err)
+		}
+	}
+	return errs.err()
+}
+
+// bindField binds the i-th field of the struct rv
+func (b *Binder) bindField(r Registrar, rv reflect.Value, i int, group []string, persistent bool) error {
+	rt := rv.Type()
+	if !rt.Field(i).IsExported() {
+		return nil
+	}
+	ft := rt.Field(i)
+	tag := ft.Tag.Get(TagName)
+	if tag == "-" || isCommandField(ft) || isArgField(ft) {
+		return nil
+	}
+	fv := rv.Field(i)
+	// nilPtr is a nil pointer field kept nil by NilPointers, fv is then a new element allocated only when the tag has a value
+	var nilPtr reflect.Value
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() && b.NilPointers && !isGroupType(fv.Type().Elem()) {
+			nilPtr = fv
+			fv = reflect.New(fv.Type().Elem()).Elem()
+		} else {
+			if fv.IsNil() {
+				fv.Set(reflect.New(fv.Type().Elem()))
+			}
+			fv = fv.Elem()
+		}
+	}
+	var flagTag *PFlagTag
+	var err error
+	if tag == "" {
+		if flagTag = methodTag(rv.Field(i)); flagTag == nil {
+			if !isGroupType(ft.Type) {
+				return nil
+			}
+			flagTag = new(PFlagTag)
+		}
+	} else if flagTag, err = scanTagFor(tag, r.Shorthands()); err != nil {
+		return &TagError{Struct: rt, Field: ft.Name, Tag: tag, Err: err}
+	}
+	if !r.Shorthands() || flagTag.Shorthand == "-" {
+		flagTag.Shorthand = ""
+	}
+	flagTag.Persistent = flagTag.Persistent || persistent
+	groupName := flagTag.Name
+	if isGroupType(fv.Type()) {
+		if groupName != "" {
+			group = append(group[:len(group):len(group)], groupName)
+		}
+		return b.bindStruct(r, fv, group, flagTag.Persistent)
+	}
+	if flagTag.Name != "" {
+		flagTag.Name = strings.Join(append(group[:len(group):len(group)], flagTag.Name), ".")
+	}
+	flagTag.Env = b.envName(flagTag.Name, flagTag.Env)
+	flagTag.Usage = envUsage(flagTag.Usage, flagTag.Env)
+	field := rt.String() + "." + ft.Name
+	var value pflag.Value
+	var native bool
+	if nilPtr.IsValid() && flagTag.Value == "" {
+		value, err = newPtrValue(nilPtr, flagTag)
+		fv = nilPtr
+	} else {
+		if nilPtr.IsValid() {
+			nilPtr.Set(fv.Addr())
+		}
+		value, native, err = newValue(fv, flagTag)
+	}
+	if errors.Is(err, errUnsupportedType) {
+		return &UnsupportedTypeError{Struct: rt, Field: ft.Name, Tag: tag, Type: fv.Type()}
+	} else if err != nil {
+		return &TagError{Struct: rt, Field: ft.Name, Tag: tag, Err: err}
+	}
+	skip, err := b.checkFlag(r, flagTag, field)
+	if _, ok := err.(*ConflictError); !ok && err != nil {
+		return &TagError{Struct: rt, Field: ft.Name, Tag: tag, Err: err}
+	} else if err != nil || skip {
+		return err
+	}
+	if flagTag.Value != "" || !b.KeepValues {
+		if err = setDefault(value, fv, flagTag.Value, native); err != nil {
+			return &TagError{Struct: rt, Field: ft.Name, Tag: tag, Err: err}
+		}
+	}
+	// the environment is read before the flag is registered, so that the default shown by PrintDefaults is the value in effect
+	envValue, fromEnv, err := setFromEnv(value, fv, flagTag.Env)
+	if err != nil {
+		return &EnvError{Struct: rt, Field: ft.Name, Flag: flagTag.Name, Env: flagTag.Env, Value: envValue, Err: err}
+	}
+	registered, err := r.Register(&Flag{
+		Name:      flagTag.Name,
+		Shorthand: flagTag.Shorthand,
+		Usage:     flagTag.Usage,
+		Tag:       flagTag,
+		Field:     fv,
+		Value:     value,
+		native:    native,
+	})
+	if err != nil {
+		return err
+	}
+	name := flagTag.Name
+	bd := &binding{
+		name:    name,
+		field:   field,
+		target:  fv,
+		tag:     flagTag,
+		value:   registered,
+		changed: func() bool { return r.Changed(name) },
+	}
+	if fromEnv {
+		bd.source = Source{Kind: SourceEnv, Name: flagTag.Env}
+	}
+	b.bindings = append(b.bindings, bd)
+	return nil
+}
+
+// methodTag returns the tag given by the GetPFlagTag or GetFlagTag method of the field v, nil when it has neither
+func methodTag(v reflect.Value) *PFlagTag {
+	flagTag := new(PFlagTag)
+	var result IFlagTag
+	switch x := v.Interface().(type) {
+	case GetPFlagTag:
+		t := x.GetPFlagTag()
+		flagTag.Shorthand = t.GetShorthand()
+		result = t
+	case GetFlagTag:
+		result = x.GetFlagTag()
+	default:
+		return nil
+	}
+	flagTag.Name = result.GetName()
+	flagTag.Value = result.GetValue()
+	flagTag.Usage = result.GetUsage()
+	if e, ok := result.(envFlagTag); ok {
+		flagTag.Env = e.GetEnv()
+	}
+	return flagTag
+}
+
+// scanTagFor parses the tag s, with a shorthand key when shorthands is set
+func scanTagFor(s string, shorthands bool) (*PFlagTag, error) {
+	if shorthands {
+		return scanPFlagTag(s)
+	}
+	flagTag, err := scanFlagTag(s)
+	if err != nil {
+		return nil, err
+	}
+	return &PFlagTag{
+		Name:     flagTag.Name,
+		Value:    flagTag.Value,
+		Usage:    flagTag.Usage,
+		Env:      flagTag.Env,
+		Encoding: flagTag.Encoding,
+		Layout:   flagTag.Layout,
+	}, nil
+}
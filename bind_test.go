@@ -0,0 +1,111 @@
+package bindflags
+
+import (
+	"errors"
+	"flag"
+	"testing"
+	"time"
+)
+
+// mapRegistrar is a Registrar for a minimal flag library keeping its flags in a map
+type mapRegistrar struct {
+	flags   map[string]*Flag
+	changed map[string]bool
+}
+
+func newMapRegistrar() *mapRegistrar {
+	return &mapRegistrar{flags: make(map[string]*Flag), changed: make(map[string]bool)}
+}
+
+func (r *mapRegistrar) Shorthands() bool {
+	return true
+}
+
+func (r *mapRegistrar) Lookup(name string) flag.Value {
+	if f, ok := r.flags[name]; ok {
+		return f.Value
+	}
+	return nil
+}
+
+func (r *mapRegistrar) LookupShorthand(shorthand string) (string, flag.Value) {
+	for _, f := range r.flags {
+		if f.Shorthand == shorthand {
+			return f.Name, f.Value
+		}
+	}
+	return "", nil
+}
+
+func (r *mapRegistrar) Register(f *Flag) (flag.Value, error) {
+	if f.Name == "reserved" {
+		return nil, errors.New("reserved flag name")
+	}
+	r.flags[f.Name] = f
+	return f.Value, nil
+}
+
+func (r *mapRegistrar) Changed(name string) bool {
+	return r.changed[name]
+}
+
+func (r *mapRegistrar) set(name, value string) error {
+	r.changed[name] = true
+	return r.flags[name].Value.Set(value)
+}
+
+func TestBind(t *testing.T) {
+	t.Setenv("TEST_BIND_TIMEOUT", "1m")
+	r := newMapRegistrar()
+	c := &struct {
+		Host    string        `flag:"name:host;shorthand:h;value:localhost"`
+		Timeout time.Duration `flag:"name:timeout;value:30s;env:TEST_BIND_TIMEOUT"`
+		DB      struct {
+			Ports []int `flag:"name:ports;value:[5432]"`
+		} `flag:"name:db"`
+	}{}
+	b := new(Binder)
+	if err := b.Bind(r, c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Host != "localhost" || c.Timeout != time.Minute || len(c.DB.Ports) != 1 || c.DB.Ports[0] != 5432 {
+		t.Fatalf("unexpected defaults: %#v", c)
+	}
+	if name, _ := r.LookupShorthand("h"); name != "host" {
+		t.Fatalf("unexpected shorthand flag %q", name)
+	}
+	if err := r.set("db.ports", "1,2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.set("host", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if c.Host != "example.com" || len(c.DB.Ports) != 2 {
+		t.Fatalf("unexpected values: %#v", c)
+	}
+	if err := b.Bind(newMapRegistrar(), &struct {
+		Host string `flag:"name:host;shorthand:h"`
+		Addr string `flag:"name:addr;shorthand:h"`
+	}{}); err == nil {
+		t.Fatal("expected a shorthand conflict")
+	}
+	if err := Bind(newMapRegistrar(), &struct {
+		R int `flag:"name:reserved"`
+	}{}); err == nil {
+		t.Fatal("expected a register error")
+	}
+}
+
+func TestBindEnvError(t *testing.T) {
+	t.Setenv("TEST_BIND_TIMEOUT", "soon")
+	err := Bind(newMapRegistrar(), &struct {
+		Timeout time.Duration `flag:"name:timeout;env:TEST_BIND_TIMEOUT"`
+	}{})
+	var envErr *EnvError
+	if !errors.As(err, &envErr) || envErr.Field != "Timeout" || envErr.Env != "TEST_BIND_TIMEOUT" || envErr.Value != "soon" {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if errors.Unwrap(err) == nil {
+		t.Fatal("expected the cause to be wrapped")
+	}
+}
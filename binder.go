@@ -0,0 +1,108 @@
+package bindflags
+
+import (
+	"flag"
+	"reflect"
+	"strings"
+)
+
+// Binder binds structs the same way as BindPFlags and BindFlags, with options shared by every call.
+// It also remembers the flags it bound, so that config files can be applied to them after parsing.
+// The zero value is ready to use and behaves like the package level functions.
+// Binder 与 BindPFlags、BindFlags 的绑定方式相同，并携带多次调用共享的选项，零值即可使用
+type Binder struct {
+	// AutoEnv derives an environment variable name for every flag whose tag has no env key,
+	// e.g. db.max-conns becomes DB_MAX_CONNS; an env key still overrides it and env:- disables it for one field
+	AutoEnv bool
+	// EnvPrefix is prepended to the derived names, e.g. MYAPP turns db.max-conns into MYAPP_DB_MAX_CONNS
+	EnvPrefix string
+	// AppName enables config file discovery: BindPFlags and BindFlags register a --config flag,
+	// and Load merges the config files found in the locations returned by ConfigPaths
+	AppName string
+	// ConfigFlag is the name of the flag registered when AppName is set, config when empty
+	ConfigFlag string
+	// CollectErrors keeps binding after an error and returns every error of the struct tree as BindErrors
+	CollectErrors bool
+	// OnConflict tells what to do when a flag name or shorthand is already registered, ConflictFail by default
+	OnConflict ConflictPolicy
+	// KeepValues uses the current value of a field whose tag has no value as the default of its flag, so that a struct
+	// filled by a constructor keeps its defaults and PrintDefaults shows them; by default such a field is reset to zero,
+	// unless its type has its own Set or UnmarshalText method
+	KeepValues bool
+	// NilPointers keeps a nil pointer field whose tag has no value nil until its flag, environment variable or config file
+	// sets it, so that *bool or *int can tell unset from the zero value; by default every nil pointer field is allocated
+	NilPointers bool
+
+	bindings   []*binding
+	configFile string
+}
+
+// binding is a flag registered by the Binder
+type binding struct {
+	name string
+	// field is the struct field, such as main.Config.Name
+	field string
+	// target is the bound field, its value is set directly for the map values of pflag
+	target reflect.Value
+	// tag is the tag of the field, with the options of its value
+	tag     *PFlagTag
+	value   flag.Value
+	changed func() bool
+	source  Source
+}
+
+// envName returns the environment variable bound to the flag name, env is the value of the env key in the tag
+func (b *Binder) envName(name, env string) string {
+	if env == "-" {
+		return ""
+	}
+	if env != "" || !b.AutoEnv || name == "" {
+		return env
+	}
+	env = strings.NewReplacer(".", "_", "-", "_").Replace(strings.ToUpper(name))
+	if b.EnvPrefix != "" {
+		env = strings.ToUpper(strings.TrimSuffix(b.EnvPrefix, "_")) + "_" + env
+	}
+	return env
+}
+
+// check reports whether the config value v can be set on the flag, by setting it on a new field of the same type
+func (bd *binding) check(v any) error {
+	t := bd.target.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	field := reflect.New(t).Elem()
+	value, _, err := newValue(field, bd.tag)
+	if err != nil {
+		return err
+	}
+	if cur := reflect.Indirect(bd.target); cur.IsValid() && isMethodValue(value, field) {
+		// a type with its own Set may need its current state, like the values it accepts
+		field.Set(cur)
+	}
+	return setConfigValue(value, field, v)
+}
+
+// scope returns a copy of the Binder applying config files only to the flags for which keep returns true
+func (b *Binder) scope(keep func(bd *binding) bool) *Binder {
+	scoped := *b
+	scoped.bindings = nil
+	for _, bd := range b.bindings {
+		if keep(bd) {
+			scoped.bindings = append(scoped.bindings, bd)
+		}
+	}
+	return &scoped
+}
+
+// lookup returns the bindings with the flag name, there may be several when the Binder is used on several FlagSets
+func (b *Binder) lookup(name string) []*binding {
+	var result []*binding
+	for _, bd := range b.bindings {
+		if bd.name == name {
+			result = append(result, bd)
+		}
+	}
+	return result
+}
@@ -0,0 +1,143 @@
+package bindflags
+
+import (
+	"errors"
+	"flag"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+type autoEnvConfig struct {
+	Name string `flag:"name:name;value:app"`
+	DB   struct {
+		Host     string `flag:"name:host;value:localhost"`
+		MaxConns int    `flag:"name:max-conns;value:10"`
+		User     string `flag:"name:user;env:DATABASE_USER"`
+		Password string `flag:"name:password;env:-"`
+	} `flag:"db"`
+}
+
+func TestBinderAutoEnv(t *testing.T) {
+	t.Setenv("MYAPP_NAME", "svc")
+	t.Setenv("MYAPP_DB_MAX_CONNS", "50")
+	t.Setenv("MYAPP_DB_USER", "ignored")
+	t.Setenv("DATABASE_USER", "root")
+	t.Setenv("MYAPP_DB_PASSWORD", "secret")
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	c := new(autoEnvConfig)
+	b := &Binder{AutoEnv: true, EnvPrefix: "myapp"}
+	if err := b.BindPFlags(f, c); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Parse([]string{"--name", "cli"}); err != nil {
+		t.Fatal(err)
+	}
+	if c.Name != "cli" || c.DB.Host != "localhost" || c.DB.MaxConns != 50 || c.DB.User != "root" || c.DB.Password != "" {
+		t.Fatalf("unexpected values: %#v", c)
+	}
+	if u := f.Lookup("db.max-conns").Usage; u != "[$MYAPP_DB_MAX_CONNS]" {
+		t.Fatalf("unexpected usage %q", u)
+	}
+}
+
+func TestBinderCollectErrors(t *testing.T) {
+	c := &struct {
+		Port  int      `flag:"name:port;value:http"`
+		Bad   string   `flag:"name:bad;unknown:1"`
+		Valid string   `flag:"name:valid"`
+		Chan  chan int `flag:"name:chan"`
+		DB    struct {
+			Timeout float64 `flag:"name:timeout;value:soon"`
+		} `flag:"db"`
+	}{}
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	err := (&Binder{CollectErrors: true}).BindPFlags(f, c)
+	var errs BindErrors
+	if !errors.As(err, &errs) || len(errs) != 4 {
+		t.Fatalf("expected 4 errors, got %v", err)
+	}
+	var typeErr *UnsupportedTypeError
+	if !errors.As(err, &typeErr) || typeErr.Field != "Chan" {
+		t.Fatalf("expected UnsupportedTypeError, got %v", err)
+	}
+	if typeErr = nil; !errs.As(&typeErr) || typeErr.Field != "Chan" || !errs.Is(errs[0]) {
+		t.Fatalf("As and Is should look at every error, got %v", typeErr)
+	}
+	var tagErr *TagError
+	if !errors.As(errs[3], &tagErr) || tagErr.Field != "Timeout" {
+		t.Fatalf("expected TagError of the nested field, got %v", errs[3])
+	}
+	if f.Lookup("valid") == nil {
+		t.Fatal("valid fields should still be bound")
+	}
+	if err = new(Binder).BindPFlags(pflag.NewFlagSet("test", pflag.ContinueOnError), c); !errors.As(err, &tagErr) || tagErr.Field != "Port" {
+		t.Fatalf("expected the first error only, got %v", err)
+	}
+}
+
+type keepConfig struct {
+	Workers int               `flag:"name:workers"`
+	Host    string            `flag:"name:host;value:localhost"`
+	Tags    []string          `flag:"name:tags"`
+	Labels  map[string]string `flag:"name:labels"`
+	Timeout time.Duration     `flag:"name:timeout"`
+	Since   time.Time         `flag:"name:since"`
+	Addr    netip.Addr        `flag:"name:addr"`
+	DB      *struct {
+		Port int `flag:"name:port"`
+	} `flag:"db"`
+}
+
+func newKeepConfig() *keepConfig {
+	c := &keepConfig{Workers: 8, Host: "example.com", Tags: []string{"a", "b"}, Labels: map[string]string{"env": "dev"}, Timeout: time.Minute}
+	c.Since = time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	c.Addr = netip.MustParseAddr("10.0.0.1")
+	c.DB = &struct {
+		Port int `flag:"name:port"`
+	}{Port: 5432}
+	return c
+}
+
+func TestBinderKeepValues(t *testing.T) {
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	c := newKeepConfig()
+	if err := (&Binder{KeepValues: true}).BindPFlags(f, c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Workers != 8 || c.Host != "localhost" || len(c.Tags) != 2 || c.Labels["env"] != "dev" || c.Timeout != time.Minute || c.DB.Port != 5432 {
+		t.Fatalf("unexpected defaults: %#v", c)
+	}
+	for name, def := range map[string]string{"workers": "8", "host": "localhost", "tags": "[a,b]", "labels": "[env=dev]", "timeout": "1m0s", "db.port": "5432",
+		"since": "2024-01-02T00:00:00Z", "addr": "10.0.0.1"} {
+		if d := f.Lookup(name).DefValue; d != def {
+			t.Errorf("unexpected default of %s: %q", name, d)
+		}
+	}
+	if err := f.Parse([]string{"--tags", "c", "--labels", "k=v"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Tags) != 1 || c.Tags[0] != "c" || len(c.Labels) != 1 || c.Labels["k"] != "v" {
+		t.Fatalf("the command line should replace the defaults: %v %v", c.Tags, c.Labels)
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	c = newKeepConfig()
+	if err := (&Binder{KeepValues: true}).BindFlags(fs, c); err != nil {
+		t.Fatal(err)
+	}
+	if d := fs.Lookup("workers").DefValue; d != "8" || c.Workers != 8 {
+		t.Fatalf("unexpected default %q", d)
+	}
+
+	c = newKeepConfig()
+	MustBindPFlags(pflag.NewFlagSet("test", pflag.ContinueOnError), c)
+	if c.Workers != 0 || len(c.Tags) != 0 || c.DB.Port != 0 || !c.Since.IsZero() {
+		t.Fatalf("fields should be reset without KeepValues: %#v", c)
+	}
+	if c.Addr.String() != "10.0.0.1" {
+		t.Fatalf("a field with its own UnmarshalText should be kept: %v", c.Addr)
+	}
+}
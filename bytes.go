@@ -0,0 +1,68 @@
+package bindflags
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// isBytesType reports whether t is a []byte bound with the encoding option, net.IP and net.IPMask are not
+func isBytesType(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 && !pflagNetTypes[t]
+}
+
+// checkEncoding returns an error when encoding is not an encoding of []byte fields
+func checkEncoding(encoding string) error {
+	switch encoding {
+	case "", "hex", "base64":
+		return nil
+	}
+	return fmt.Errorf("invalid encoding %q, must be hex or base64", encoding)
+}
+
+// decodeBytes decodes s with the encoding hex, the default, or base64
+func decodeBytes(s, encoding string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if encoding == "base64" {
+		return base64.StdEncoding.DecodeString(s)
+	}
+	return hex.DecodeString(s)
+}
+
+func encodeBytes(b []byte, encoding string) string {
+	if encoding == "base64" {
+		return base64.StdEncoding.EncodeToString(b)
+	}
+	return strings.ToUpper(hex.EncodeToString(b))
+}
+
+// bytesValue is a flag.Value for a []byte written in hex or base64
+type bytesValue struct {
+	p        *[]byte
+	encoding string
+}
+
+func (v *bytesValue) Set(s string) error {
+	b, err := decodeBytes(s, v.encoding)
+	if err != nil {
+		return err
+	}
+	*v.p = b
+	return nil
+}
+
+func (v *bytesValue) String() string {
+	if v.p == nil {
+		return ""
+	}
+	return encodeBytes(*v.p, v.encoding)
+}
+
+func (v *bytesValue) Type() string {
+	if v.encoding == "base64" {
+		return "bytesBase64"
+	}
+	return "bytesHex"
+}
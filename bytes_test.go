@@ -0,0 +1,73 @@
+package bindflags
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+type bytesConfig struct {
+	Key   []byte `flag:"name:key;value:DEADBEEF"`
+	Salt  []byte `flag:"name:salt;shorthand:s;value:c2FsdA==;encoding:base64"`
+	Nonce []byte `flag:"name:nonce;encoding:base64;env:TEST_NONCE"`
+}
+
+func TestBindPFlagsBytes(t *testing.T) {
+	os.Setenv("TEST_NONCE", "AQID")
+	defer os.Unsetenv("TEST_NONCE")
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	c := new(bytesConfig)
+	b := new(Binder)
+	if err := b.BindPFlags(f, c); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(c.Key, []byte{0xde, 0xad, 0xbe, 0xef}) || string(c.Salt) != "salt" || !bytes.Equal(c.Nonce, []byte{1, 2, 3}) {
+		t.Fatalf("unexpected defaults: %#v", c)
+	}
+	if typ := f.Lookup("salt").Value.Type(); typ != "bytesBase64" {
+		t.Fatalf("unexpected type %q", typ)
+	}
+	if err := f.Parse([]string{"--key", "0102", "-s", "cGVwcGVy"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.LoadConfig(strings.NewReader(`{"key":"ffff","nonce":"BAU="}`)); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(c.Key, []byte{1, 2}) || string(c.Salt) != "pepper" || !bytes.Equal(c.Nonce, []byte{1, 2, 3}) {
+		t.Fatalf("unexpected values: %#v", c)
+	}
+	if err := f.Set("key", "xyz"); err == nil {
+		t.Fatal("expected an invalid value error")
+	}
+
+	var tagErr *TagError
+	bad := &struct {
+		Key []byte `flag:"name:key;encoding:base32"`
+	}{}
+	if err := BindPFlags(pflag.NewFlagSet("test", pflag.ContinueOnError), bad); !errors.As(err, &tagErr) {
+		t.Fatalf("expected a TagError, got %v", err)
+	}
+}
+
+func TestBindFlagsBytes(t *testing.T) {
+	f := flag.NewFlagSet("test", flag.ContinueOnError)
+	c := &struct {
+		Key  []byte `flag:"name:key;value:00ff"`
+		Salt []byte `flag:"name:salt;encoding:base64"`
+	}{}
+	MustBindFlags(f, c)
+	if !bytes.Equal(c.Key, []byte{0, 0xff}) {
+		t.Fatalf("unexpected default %v", c.Key)
+	}
+	if err := f.Parse([]string{"-key", "10", "-salt", "c2FsdA=="}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(c.Key, []byte{0x10}) || string(c.Salt) != "salt" {
+		t.Fatalf("unexpected values: %#v", c)
+	}
+}
@@ -0,0 +1,207 @@
+package bindflags
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// BindCommand binds the struct a to the flags of a cobra command, see Binder.BindCommand
+// BindCommand 把结构体绑定到 cobra 命令的标志上
+func BindCommand(cmd *cobra.Command, a any, group ...string) error {
+	return new(Binder).BindCommand(cmd, a, group...)
+}
+
+// BindCommand binds the struct a to cmd.Flags(), the fields whose tag has the persistent option,
+// like `flag:"name:verbose;persistent"`, go to cmd.PersistentFlags() together with the fields of a nested struct marked persistent.
+// The fields with an arg tag receive the positional arguments, see BindArgs, and give cmd an Args checking their number.
+// It then installs a PreRunE that merges the config files of Load into the flags of cmd, fills the arguments and runs Validate before RunE,
+// an existing PreRunE or PreRun of cmd is called afterwards.
+// BindCommand 把结构体绑定到 cmd.Flags()，带 persistent 选项的字段绑定到 cmd.PersistentFlags()；
+// 并设置 PreRunE，在 RunE 之前加载配置文件并执行 Validate，原有的 PreRunE 或 PreRun 随后仍会被调用
+func (b *Binder) BindCommand(cmd *cobra.Command, a any, group ...string) error {
+	rv := reflect.ValueOf(a)
+	if rv.Kind() != reflect.Ptr {
+		return errors.New("a must be a pointer")
+	}
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		return errors.New("a must be a struct")
+	}
+	err := b.Bind(commandRegistrar(cmd, cmd.Flags()), a, group...)
+	if err != nil {
+		return err
+	}
+	if err = bindCommandArgs(cmd, a); err != nil {
+		return err
+	}
+	preRunE, preRun := cmd.PreRunE, cmd.PreRun
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := b.commandScope(cmd).Load(); err != nil {
+			return err
+		}
+		if err := BindArgs(args, a); err != nil {
+			return err
+		}
+		if err := Validate(a, group...); err != nil {
+			return err
+		}
+		if preRunE != nil {
+			return preRunE(cmd, args)
+		}
+		if preRun != nil {
+			preRun(cmd, args)
+		}
+		return nil
+	}
+	return nil
+}
+
+// MustBindCommand is like BindCommand but panics if an error occurs
+func MustBindCommand(cmd *cobra.Command, a any, group ...string) {
+	if err := BindCommand(cmd, a, group...); err != nil {
+		panic(err)
+	}
+}
+
+// CommandTagName is the tag key turning a struct field into a subcommand, such as `cmd:"serve;start the server"`
+var CommandTagName = "cmd"
+
+// commandNames are the keys of the command tag, in the order of the values without a key
+var commandNames = []string{"use", "short"}
+
+// Runner is implemented by the pointer to a command struct given to NewCommand, Run becomes the RunE of the command
+// and is called on the struct filled from the flags of the command
+type Runner interface {
+	Run(cmd *cobra.Command, args []string) error
+}
+
+// isCommandField reports whether the field is a subcommand rather than flags
+func isCommandField(ft reflect.StructField) bool {
+	_, ok := ft.Tag.Lookup(CommandTagName)
+	return ok
+}
+
+// NewCommand builds a cobra command tree from the struct a, see Binder.NewCommand
+// NewCommand 根据结构体 a 构建 cobra 命令树
+func NewCommand(use string, a any) (*cobra.Command, error) {
+	return new(Binder).NewCommand(use, a)
+}
+
+// NewCommand builds a cobra command tree from the struct a. Every struct field with a cmd tag, such as
+// `cmd:"serve;start the server"` or `cmd:"use:serve;short:start the server"`, becomes a subcommand built the same way.
+// The flags of a command with subcommands are persistent, so the subcommands inherit them, the flags of the other commands are local.
+// When the pointer to a command struct implements Runner, its Run is the RunE of the command.
+// The PreRunE of every command merges the config files of Load into the flags of the command and its parents,
+// and runs Validate on the command struct and its parents.
+// NewCommand 根据结构体 a 构建 cobra 命令树，带 cmd tag 的结构体字段成为子命令；有子命令的命令的标志是持久标志；
+// 命令结构体的指针实现 Runner 时，其 Run 方法作为命令的 RunE
+func (b *Binder) NewCommand(use string, a any) (*cobra.Command, error) {
+	rv := reflect.ValueOf(a)
+	if rv.Kind() != reflect.Ptr {
+		return nil, errors.New("a must be a pointer")
+	}
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		return nil, errors.New("a must be a struct")
+	}
+	return b.newCommand(&cobra.Command{Use: use}, rv, nil)
+}
+
+// newCommand binds the command struct rv to cmd, parents are the structs of the parent commands
+func (b *Binder) newCommand(cmd *cobra.Command, rv reflect.Value, parents []any) (*cobra.Command, error) {
+	rt := rv.Type()
+	var subs []int
+	for i := 0; i < rt.NumField(); i++ {
+		if rt.Field(i).IsExported() && isCommandField(rt.Field(i)) {
+			subs = append(subs, i)
+		}
+	}
+	flags := cmd.Flags()
+	if len(subs) > 0 {
+		flags = cmd.PersistentFlags()
+	}
+	r := commandRegistrar(cmd, flags)
+	var err error
+	if parents == nil {
+		err = b.addConfigFlag(r)
+	}
+	if err == nil {
+		err = b.bindStruct(r, rv, nil, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+	chain := append(parents[:len(parents):len(parents)], rv.Addr().Interface())
+	if err = bindCommandArgs(cmd, rv.Addr().Interface()); err != nil {
+		return nil, err
+	}
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := b.commandScope(cmd).Load(); err != nil {
+			return err
+		}
+		if err := BindArgs(args, rv.Addr().Interface()); err != nil {
+			return err
+		}
+		for _, a := range chain {
+			if err := Validate(a); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if r, ok := rv.Addr().Interface().(Runner); ok {
+		cmd.RunE = r.Run
+	}
+	for _, i := range subs {
+		ft := rt.Field(i)
+		tag := ft.Tag.Get(CommandTagName)
+		kv, err := scanTag(tag, commandNames)
+		if err != nil {
+			return nil, &TagError{Struct: rt, Field: ft.Name, Tag: tag, Err: err}
+		}
+		fv := rv.Field(i)
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				fv.Set(reflect.New(fv.Type().Elem()))
+			}
+			fv = fv.Elem()
+		}
+		if fv.Kind() != reflect.Struct {
+			return nil, &UnsupportedTypeError{Struct: rt, Field: ft.Name, Tag: tag, Type: fv.Type()}
+		}
+		if kv["use"] == "" {
+			kv["use"] = strings.ToLower(ft.Name)
+		}
+		// the subcommand is added first so that its flags are checked against the persistent flags of its parents
+		sub := &cobra.Command{Use: kv["use"], Short: kv["short"]}
+		cmd.AddCommand(sub)
+		if _, err = b.newCommand(sub, fv, chain); err != nil {
+			return nil, err
+		}
+	}
+	return cmd, nil
+}
+
+// commandScope returns a copy of the Binder applying config files only to the flags of cmd when it runs:
+// its own flags and the persistent flags of its parents, which cobra merges into cmd.Flags() before PreRunE.
+// A sibling command bound by the same Binder may use the same names.
+func (b *Binder) commandScope(cmd *cobra.Command) *Binder {
+	return b.scope(func(bd *binding) bool {
+		f := cmd.Flags().Lookup(bd.name)
+		return f != nil && f.Value == bd.value
+	})
+}
+
+// commandRegistrar returns the Registrar binding to flags, a flag set of cmd, and to the persistent flags of cmd.
+// The persistent flags of the parents of cmd are looked up for conflicts, since cmd inherits them.
+func commandRegistrar(cmd *cobra.Command, flags *pflag.FlagSet) *pflagRegistrar {
+	r := &pflagRegistrar{flags: flags, persistent: cmd.PersistentFlags()}
+	for p := cmd.Parent(); p != nil; p = p.Parent() {
+		r.inherited = append(r.inherited, p.PersistentFlags())
+	}
+	return r
+}
@@ -0,0 +1,200 @@
+package bindflags
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+type commandConfig struct {
+	Verbose bool   `flag:"name:verbose;shorthand:v;persistent"`
+	Level   string `flag:"name:level;value:info;oneof:debug|info|warn"`
+	Log     struct {
+		File string `flag:"name:file"`
+	} `flag:"name:log;persistent"`
+}
+
+func TestBindCommand(t *testing.T) {
+	c := new(commandConfig)
+	var calls []string
+	cmd := &cobra.Command{
+		Use:     "app",
+		PreRun:  func(cmd *cobra.Command, args []string) { calls = append(calls, "prerun") },
+		RunE:    func(cmd *cobra.Command, args []string) error { calls = append(calls, "run"); return nil },
+		Args:    cobra.NoArgs,
+		Version: "1",
+	}
+	if err := BindCommand(cmd, c); err != nil {
+		t.Fatal(err)
+	}
+	if cmd.PersistentFlags().Lookup("verbose") == nil || cmd.PersistentFlags().Lookup("log.file") == nil || cmd.LocalNonPersistentFlags().Lookup("level") == nil {
+		t.Fatal("persistent fields should be bound to the persistent flags")
+	}
+	cmd.SetArgs([]string{"-v", "--level", "debug", "--log.file", "a.log"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Verbose || c.Level != "debug" || c.Log.File != "a.log" {
+		t.Fatalf("unexpected values: %#v", c)
+	}
+	if len(calls) != 2 || calls[0] != "prerun" {
+		t.Fatalf("the existing PreRun should be chained, got %v", calls)
+	}
+
+	cmd.SetArgs([]string{"--level", "trace"})
+	cmd.SilenceErrors, cmd.SilenceUsage = true, true
+	var ve *ValidationError
+	if err := cmd.Execute(); !errors.As(err, &ve) || ve.Flag != "level" {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+}
+
+type appCommand struct {
+	Verbose bool         `flag:"name:verbose;shorthand:v"`
+	Serve   serveCommand `cmd:"serve;start the server"`
+	Client  *struct {
+		Addr string `flag:"name:addr;value:localhost:80"`
+	} `cmd:""`
+}
+
+type serveCommand struct {
+	Port int `flag:"name:port;shorthand:p;value:80;max:65535"`
+	ran  *serveCommand
+}
+
+func (s *serveCommand) Run(cmd *cobra.Command, args []string) error {
+	s.ran = s
+	return nil
+}
+
+func TestNewCommand(t *testing.T) {
+	c := new(appCommand)
+	root, err := NewCommand("app", c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root.PersistentFlags().Lookup("verbose") == nil {
+		t.Fatal("the flags of a parent command should be persistent")
+	}
+	serve, _, err := root.Find([]string{"serve"})
+	if err != nil || serve.Short != "start the server" || serve.LocalNonPersistentFlags().Lookup("port") == nil {
+		t.Fatalf("unexpected serve command %v", err)
+	}
+	if client, _, err := root.Find([]string{"client"}); err != nil || client.Flags().Lookup("addr") == nil {
+		t.Fatalf("unexpected client command %v", err)
+	}
+	root.SetArgs([]string{"serve", "-v", "-p", "8080"})
+	if err = root.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Verbose || c.Serve.Port != 8080 || c.Serve.ran != &c.Serve {
+		t.Fatalf("unexpected values: %#v", c)
+	}
+
+	root.SetArgs([]string{"serve", "-p", "70000"})
+	root.SilenceErrors, root.SilenceUsage = true, true
+	var ve *ValidationError
+	if err = root.Execute(); !errors.As(err, &ve) || ve.Flag != "port" {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+}
+
+type shorthandCommand struct {
+	Verbose bool `flag:"name:verbose;shorthand:v"`
+	Sub     struct {
+		Version bool `flag:"name:version;shorthand:v"`
+	} `cmd:"sub"`
+}
+
+func TestNewCommandInheritedConflict(t *testing.T) {
+	_, err := NewCommand("app", new(shorthandCommand))
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || conflict.Shorthand != "v" || conflict.Other != "verbose" ||
+		conflict.OtherField != "bindflags.shorthandCommand.Verbose" {
+		t.Fatalf("expected a shorthand conflict with the parent, got %v", err)
+	}
+
+	c := new(shorthandCommand)
+	root, err := (&Binder{OnConflict: ConflictDropShorthand}).NewCommand("app", c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.SetArgs([]string{"sub", "-v", "--version"})
+	if err = root.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Verbose || !c.Sub.Version {
+		t.Fatalf("unexpected values: %#v", c)
+	}
+}
+
+type scopedCommand struct {
+	Serve struct {
+		Timeout time.Duration `flag:"name:timeout"`
+	} `cmd:"serve"`
+	Client struct {
+		Timeout int `flag:"name:timeout"`
+	} `cmd:"client"`
+}
+
+func TestNewCommandConfigScope(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("timeout: 30s\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := new(scopedCommand)
+	root, err := (&Binder{AppName: "bindflags-test-scope"}).NewCommand("app", c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serve, _, _ := root.Find([]string{"serve"})
+	serve.RunE = func(cmd *cobra.Command, args []string) error { return nil }
+	root.SetArgs([]string{"serve", "--config", path})
+	if err = root.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Serve.Timeout != 30*time.Second || c.Client.Timeout != 0 {
+		t.Fatalf("the config file should only set the flags of serve: %#v", c)
+	}
+}
+
+func TestBindCommandConfigScope(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"name":"x"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	type config struct {
+		Name string `flag:"name:name"`
+	}
+	b := &Binder{AppName: "bindflags-test-scope"}
+	root := &cobra.Command{Use: "app"}
+	ca, cb := new(config), new(config)
+	run := func(cmd *cobra.Command, args []string) error { return nil }
+	a, sb := &cobra.Command{Use: "a", RunE: run}, &cobra.Command{Use: "b", RunE: run}
+	root.AddCommand(a, sb)
+	if err := b.BindCommand(a, ca); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.BindCommand(sb, cb); err != nil {
+		t.Fatal(err)
+	}
+	root.SetArgs([]string{"a", "--config", path})
+	if err := root.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if ca.Name != "x" || cb.Name != "" {
+		t.Fatalf("the config file should only set the flags of a: %#v %#v", ca, cb)
+	}
+	if src, _ := b.Origin("name"); src.Kind != SourceConfig {
+		t.Fatalf("unexpected source %v", src)
+	}
+	if src := b.Sources()["name"]; src.Kind != SourceConfig {
+		t.Fatalf("unexpected source %v", src)
+	}
+}
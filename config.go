@@ -0,0 +1,248 @@
+package bindflags
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadConfig reads a JSON document and applies it to the flags bound by the Binder, call it after FlagSet.Parse.
+// Nested objects map to nested struct groups, {"db":{"host":"x"}} and {"db.host":"x"} both set the flag db.host.
+// A flag given on the command line or filled from an environment variable keeps its value, keys without a flag are ignored.
+// Every value is checked before any flag is set, so an error leaves the flags as they were.
+// LoadConfig 读取 JSON 文档并应用到 Binder 绑定的标志上，需在 FlagSet.Parse 之后调用；命令行或环境变量给出的值优先于文件
+func (b *Binder) LoadConfig(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("config: %v", err)
+	}
+	return b.loadConfig(data, ".json", "")
+}
+
+// LoadConfigFile is like LoadConfig, the decoder is picked from the file extension: .json, .yaml, .yml or .toml.
+// LoadConfigFile 与 LoadConfig 相同，根据文件扩展名选择 JSON、YAML 或 TOML 解码
+func (b *Binder) LoadConfigFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config: %v", err)
+	}
+	if err = b.loadConfig(data, strings.ToLower(filepath.Ext(path)), path); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	return nil
+}
+
+// configExts are the config file extensions searched by Load, in the order they are merged
+var configExts = []string{".json", ".yaml", ".yml", ".toml"}
+
+// ConfigPaths returns the config files Load merges when they exist, in merge order; a later file overrides an earlier one:
+//
+//	/etc/<AppName>/config.{json,yaml,yml,toml}
+//	$XDG_CONFIG_HOME/<AppName>/config.{json,yaml,yml,toml} ($HOME/.config when XDG_CONFIG_HOME is unset)
+//	./config.{json,yaml,yml,toml}
+//	the file given by --config, which must exist
+//
+// ConfigPaths 按合并顺序返回 Load 查找的配置文件，后面的文件覆盖前面的
+func (b *Binder) ConfigPaths() []string {
+	if b.AppName == "" {
+		return nil
+	}
+	var dirs = []string{filepath.Join("/etc", b.AppName)}
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		dirs = append(dirs, filepath.Join(xdg, b.AppName))
+	} else if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".config", b.AppName))
+	}
+	dirs = append(dirs, ".")
+	var paths []string
+	for _, dir := range dirs {
+		for _, ext := range configExts {
+			paths = append(paths, filepath.Join(dir, "config"+ext))
+		}
+	}
+	if b.configFile != "" {
+		paths = append(paths, b.configFile)
+	}
+	return paths
+}
+
+// Load merges the config files returned by ConfigPaths into the bound flags, call it after FlagSet.Parse.
+// Values from the command line and environment variables still win over the files.
+// Load 按 ConfigPaths 的顺序合并配置文件，需在 FlagSet.Parse 之后调用
+func (b *Binder) Load() error {
+	for _, path := range b.ConfigPaths() {
+		if path != b.configFile {
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+		}
+		if err := b.LoadConfigFile(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *Binder) configFlagName() string {
+	if b.ConfigFlag != "" {
+		return b.ConfigFlag
+	}
+	return "config"
+}
+
+// addConfigFlag registers the --config flag once per Registrar when AppName is set
+func (b *Binder) addConfigFlag(r Registrar) error {
+	name := b.configFlagName()
+	if b.AppName == "" || r.Lookup(name) != nil {
+		return nil
+	}
+	v := reflect.ValueOf(&b.configFile).Elem()
+	_, err := r.Register(&Flag{Name: name, Usage: "config file", Tag: &PFlagTag{Name: name}, Field: v, Value: &scalarValue{value: v}, native: true})
+	return err
+}
+
+// configDecoders maps a config file extension to the function decoding its content into a map[string]any
+var configDecoders = map[string]func(data []byte, v any) error{
+	".json": func(data []byte, v any) error {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		return dec.Decode(v)
+	},
+	".yaml": yaml.Unmarshal,
+	".yml":  yaml.Unmarshal,
+	".toml": toml.Unmarshal,
+}
+
+func (b *Binder) loadConfig(data []byte, ext, path string) error {
+	decode, ok := configDecoders[ext]
+	if !ok {
+		return fmt.Errorf("config: unsupported file extension %q", ext)
+	}
+	var m map[string]any
+	if err := decode(data, &m); err != nil {
+		return fmt.Errorf("config: %v", err)
+	}
+	return b.applyConfig(path, normalizeConfig(m).(map[string]any))
+}
+
+// normalizeConfig turns the types produced by the YAML and TOML decoders into the ones produced by encoding/json
+func normalizeConfig(v any) any {
+	switch x := v.(type) {
+	case map[string]any:
+		for k, item := range x {
+			x[k] = normalizeConfig(item)
+		}
+		return x
+	case map[any]any:
+		m := make(map[string]any, len(x))
+		for k, item := range x {
+			m[fmt.Sprint(k)] = normalizeConfig(item)
+		}
+		return m
+	case []map[string]any:
+		items := make([]any, len(x))
+		for i, item := range x {
+			items[i] = normalizeConfig(item)
+		}
+		return items
+	case []any:
+		for i, item := range x {
+			x[i] = normalizeConfig(item)
+		}
+		return x
+	case time.Time:
+		return x.Format(time.RFC3339Nano)
+	default:
+		return v
+	}
+}
+
+// configValue is a value of a config file for the flag of bd, named key in the file
+type configValue struct {
+	bd    *binding
+	key   string
+	value any
+}
+
+// applyConfig sets the values of m on the flags that were not given on the command line or in the environment.
+// Every value is checked first, the flags are left unchanged when one of them is invalid.
+func (b *Binder) applyConfig(path string, m map[string]any) error {
+	values := b.configValues("", m, nil)
+	for _, cv := range values {
+		if err := cv.bd.check(cv.value); err != nil {
+			return fmt.Errorf("config: invalid value %v for flag %s: %v", cv.value, cv.key, err)
+		}
+	}
+	for _, cv := range values {
+		if err := setConfigValue(cv.bd.value, cv.bd.target, cv.value); err != nil {
+			return fmt.Errorf("config: invalid value %v for flag %s: %v", cv.value, cv.key, err)
+		}
+		cv.bd.source = Source{Kind: SourceConfig, Name: cv.key, Path: path}
+	}
+	return nil
+}
+
+// configValues appends the values of m to set to values, in the order of their keys
+func (b *Binder) configValues(prefix string, m map[string]any, values []configValue) []configValue {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := m[k]
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		bindings := b.lookup(key)
+		if sub, ok := v.(map[string]any); ok && len(bindings) == 0 {
+			values = b.configValues(key, sub, values)
+			continue
+		}
+		for _, bd := range bindings {
+			if bd.source.Kind == SourceEnv || bd.changed() || v == nil {
+				continue
+			}
+			values = append(values, configValue{bd: bd, key: key, value: v})
+		}
+	}
+	return values
+}
+
+// setConfigValue sets a decoded config value on v, arrays replace the content of slice flags and objects the content of map flags
+func setConfigValue(v flag.Value, target reflect.Value, value any) error {
+	switch x := value.(type) {
+	case []any:
+		items := make([]string, len(x))
+		for i, item := range x {
+			items[i] = fmt.Sprint(item)
+		}
+		if sv, ok := v.(pflag.SliceValue); ok {
+			return sv.Replace(items)
+		}
+		return v.Set(strings.Join(items, ","))
+	case map[string]any:
+		// objects set map flags, written as k=v pairs
+		items := make([]string, 0, len(x))
+		for k, item := range x {
+			items = append(items, fmt.Sprintf("%s=%v", k, item))
+		}
+		sort.Strings(items)
+		return setValue(v, target, writeAsCSV(items))
+	default:
+		return setValue(v, target, fmt.Sprint(x))
+	}
+}
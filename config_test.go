@@ -0,0 +1,173 @@
+package bindflags
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+type fileConfig struct {
+	Name  string   `flag:"name:name;value:app"`
+	Level string   `flag:"name:level;value:info;env:TEST_CONFIG_LEVEL"`
+	Ports []int    `flag:"name:ports"`
+	Tags  []string `flag:"name:tags"`
+	DB    struct {
+		Host    string  `flag:"name:host;value:localhost"`
+		Port    int     `flag:"name:port;value:5432"`
+		Timeout float64 `flag:"name:timeout"`
+	} `flag:"db"`
+}
+
+func TestBinderLoadConfig(t *testing.T) {
+	t.Setenv("TEST_CONFIG_LEVEL", "warn")
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	c := new(fileConfig)
+	b := new(Binder)
+	if err := b.BindPFlags(f, c); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Parse([]string{"--db.port", "6543"}); err != nil {
+		t.Fatal(err)
+	}
+	doc := `{"name":"svc","level":"debug","ports":[80,443],"tags":"a,b","unknown":1,"db":{"host":"db.local","port":1,"timeout":1.5}}`
+	if err := b.LoadConfig(strings.NewReader(doc)); err != nil {
+		t.Fatal(err)
+	}
+	if c.Name != "svc" || c.Level != "warn" || c.DB.Host != "db.local" || c.DB.Port != 6543 || c.DB.Timeout != 1.5 {
+		t.Fatalf("unexpected values: %#v", c)
+	}
+	if len(c.Ports) != 2 || c.Ports[1] != 443 || len(c.Tags) != 2 || c.Tags[1] != "b" {
+		t.Fatalf("unexpected slices: %v %v", c.Ports, c.Tags)
+	}
+	if err := b.LoadConfig(strings.NewReader(`{"db.port":"x"}`)); err != nil {
+		t.Fatal("changed flag should not be set from config:", err)
+	}
+	if err := b.LoadConfig(strings.NewReader(`{"db":{"host":1,"timeout":"x"}}`)); err == nil {
+		t.Fatal("expected error for invalid value")
+	}
+}
+
+func TestBinderLoadConfigInvalid(t *testing.T) {
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	c := &struct {
+		D    time.Duration   `flag:"name:d;value:1s"`
+		Tags []string        `flag:"name:tags;value:[\"a\"]"`
+		Gate map[string]bool `flag:"name:gate"`
+		DB   struct {
+			Host string `flag:"name:host;value:localhost"`
+		} `flag:"db"`
+	}{}
+	b := new(Binder)
+	if err := b.BindPFlags(f, c); err != nil {
+		t.Fatal(err)
+	}
+	for _, doc := range []string{`{"db.host":"q","d":1000000000}`, `{"tags":["x"],"gate":{"a":"maybe"}}`, `{"a":1,"z":{"host":"q"},"d":"2s","tags":[1,2],"db":{"host":"x"},"gate":"b=no"}`} {
+		if err := b.LoadConfig(strings.NewReader(doc)); err == nil {
+			t.Fatalf("expected an error for %s", doc)
+		}
+		if c.D != time.Second || c.DB.Host != "localhost" || len(c.Tags) != 1 || c.Tags[0] != "a" || len(c.Gate) != 0 {
+			t.Fatalf("a failed load should not change the flags: %#v", c)
+		}
+		if src := b.Sources()["db.host"]; src.Kind != SourceDefault {
+			t.Fatalf("unexpected source %v", src)
+		}
+	}
+}
+
+func TestBinderLoadConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"app.yaml": "name: svc\nports: [80, 443]\nretries: [1s, 2s]\ndb:\n  host: db.local\n  timeout: 1.5\n",
+		"app.toml": "name = \"svc\"\nports = [80, 443]\nretries = [\"1s\", \"2s\"]\n[db]\nhost = \"db.local\"\ntimeout = 1.5\n",
+		"app.json": `{"name":"svc","ports":[80,443],"retries":["1s","2s"],"db":{"host":"db.local","timeout":1.5}}`,
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			c := new(struct {
+				File    fileConfig
+				Retries []time.Duration `flag:"name:retries"`
+			})
+			b := new(Binder)
+			if err := b.BindPFlags(f, c); err != nil {
+				t.Fatal(err)
+			}
+			if err := b.LoadConfigFile(path); err != nil {
+				t.Fatal(err)
+			}
+			if c.File.Name != "svc" || c.File.DB.Host != "db.local" || c.File.DB.Timeout != 1.5 || len(c.File.Ports) != 2 || c.File.Ports[1] != 443 {
+				t.Fatalf("unexpected values: %#v", c)
+			}
+			if len(c.Retries) != 2 || c.Retries[1] != 2*time.Second {
+				t.Fatalf("unexpected durations: %v", c.Retries)
+			}
+		})
+	}
+	if err := new(Binder).LoadConfigFile(filepath.Join(dir, "app.ini")); err == nil {
+		t.Fatal("expected error for unsupported extension")
+	}
+}
+
+func TestBinderLoad(t *testing.T) {
+	xdg, cwd, explicit := t.TempDir(), t.TempDir(), t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(cwd); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	const app = "bindflags-test-app"
+	write := func(path, content string) {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(xdg, app, "config.yaml"), "name: xdg\nlevel: xdg\ndb:\n  host: xdg\n")
+	write(filepath.Join(cwd, "config.toml"), "level = \"cwd\"\n[db]\nhost = \"cwd\"\n")
+	write(filepath.Join(explicit, "app.json"), `{"db":{"host":"explicit"}}`)
+
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	c := new(fileConfig)
+	b := &Binder{AppName: app}
+	if err = b.BindPFlags(f, c); err != nil {
+		t.Fatal(err)
+	}
+	if err = f.Parse([]string{"--config", filepath.Join(explicit, "app.json")}); err != nil {
+		t.Fatal(err)
+	}
+	paths := b.ConfigPaths()
+	want := []string{
+		filepath.Join("/etc", app, "config.json"),
+		filepath.Join(xdg, app, "config.json"),
+		"config.json",
+	}
+	for i, path := range want {
+		if paths[i*len(configExts)] != path {
+			t.Fatalf("unexpected merge order %v", paths)
+		}
+	}
+	if paths[len(paths)-1] != filepath.Join(explicit, "app.json") {
+		t.Fatalf("--config should be merged last: %v", paths)
+	}
+	if err = b.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Name != "xdg" || c.Level != "cwd" || c.DB.Host != "explicit" {
+		t.Fatalf("unexpected values: %#v", c)
+	}
+}
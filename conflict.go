@@ -0,0 +1,82 @@
+package bindflags
+
+import (
+	"flag"
+	"fmt"
+)
+
+// ConflictPolicy tells a Binder what to do when a flag name or shorthand is already registered on the FlagSet
+type ConflictPolicy int
+
+const (
+	// ConflictFail returns a *ConflictError, it is the default
+	ConflictFail ConflictPolicy = iota
+	// ConflictSkip leaves the second field unbound
+	ConflictSkip
+	// ConflictDropShorthand binds the second field without its shorthand; a name conflict still fails
+	ConflictDropShorthand
+)
+
+// ConflictError reports a field whose flag name or shorthand is already registered on the FlagSet
+type ConflictError struct {
+	// Flag is the dotted flag name of the field
+	Flag string
+	// Shorthand is set when the shorthand conflicts rather than the name
+	Shorthand string
+	// Field is the field being bound, such as main.Config.Name
+	Field string
+	// Other is the flag already registered
+	Other string
+	// OtherField is the field bound to Other, empty when the flag was not registered by the Binder
+	OtherField string
+}
+
+func (e *ConflictError) Error() string {
+	other := "outside bindflags"
+	if e.OtherField != "" {
+		other = "by field " + e.OtherField
+	}
+	if e.Shorthand != "" {
+		return fmt.Sprintf("bindflags: shorthand -%s of flag --%s of field %s is already used by flag --%s defined %s", e.Shorthand, e.Flag, e.Field, e.Other, other)
+	}
+	return fmt.Sprintf("bindflags: flag --%s of field %s is already defined %s", e.Flag, e.Field, other)
+}
+
+// checkFlag looks for a flag of r using the name or the shorthand of flagTag before field is bound,
+// it reports whether the field must be skipped and may clear flagTag.Shorthand depending on OnConflict
+func (b *Binder) checkFlag(r Registrar, flagTag *PFlagTag, field string) (bool, error) {
+	if other := r.Lookup(flagTag.Name); other != nil {
+		return b.conflict(&ConflictError{Flag: flagTag.Name, Field: field, Other: flagTag.Name, OtherField: b.fieldOf(other)})
+	}
+	if flagTag.Shorthand == "" {
+		return false, nil
+	}
+	if len(flagTag.Shorthand) > 1 {
+		return false, fmt.Errorf("shorthand %q is more than one ASCII character", flagTag.Shorthand)
+	}
+	if name, other := r.LookupShorthand(flagTag.Shorthand); other != nil {
+		if b.OnConflict == ConflictDropShorthand {
+			flagTag.Shorthand = ""
+			return false, nil
+		}
+		return b.conflict(&ConflictError{Flag: flagTag.Name, Shorthand: flagTag.Shorthand, Field: field, Other: name, OtherField: b.fieldOf(other)})
+	}
+	return false, nil
+}
+
+func (b *Binder) conflict(err *ConflictError) (bool, error) {
+	if b.OnConflict == ConflictSkip {
+		return true, nil
+	}
+	return false, err
+}
+
+// fieldOf returns the field bound to the flag value v, empty when v was not bound by the Binder
+func (b *Binder) fieldOf(v flag.Value) string {
+	for _, bd := range b.bindings {
+		if bd.value == v {
+			return bd.field
+		}
+	}
+	return ""
+}
@@ -0,0 +1,99 @@
+package bindflags
+
+import (
+	"errors"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+type conflictServer struct {
+	Name string `flag:"name:name;shorthand:n"`
+	Port int    `flag:"name:port;shorthand:p;value:80"`
+}
+
+type conflictClient struct {
+	Name string `flag:"name:name;shorthand:c"`
+	Peer string `flag:"name:peer;shorthand:p"`
+}
+
+func TestBinderConflict(t *testing.T) {
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	b := new(Binder)
+	if err := b.BindPFlags(f, new(conflictServer)); err != nil {
+		t.Fatal(err)
+	}
+	err := b.BindPFlags(f, new(conflictClient))
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || conflict.Flag != "name" || conflict.Shorthand != "" ||
+		conflict.Field != "bindflags.conflictClient.Name" || conflict.OtherField != "bindflags.conflictServer.Name" {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	f = pflag.NewFlagSet("test", pflag.ContinueOnError)
+	f.Bool("peer-mode", false, "")
+	f.StringP("other", "p", "", "")
+	b = &Binder{CollectErrors: true}
+	err = b.BindPFlags(f, new(conflictServer))
+	if !errors.As(err, &conflict) || conflict.Shorthand != "p" || conflict.Other != "other" || conflict.OtherField != "" {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !strings.Contains(err.Error(), "defined outside bindflags") {
+		t.Fatalf("unexpected message %q", err)
+	}
+}
+
+func TestBinderConflictPolicy(t *testing.T) {
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	b := &Binder{OnConflict: ConflictSkip}
+	server, client := new(conflictServer), new(conflictClient)
+	if err := b.BindPFlags(f, server); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.BindPFlags(f, client); err != nil {
+		t.Fatal(err)
+	}
+	if f.Lookup("peer") != nil || f.ShorthandLookup("c") != nil {
+		t.Fatal("conflicting fields should be skipped")
+	}
+
+	f = pflag.NewFlagSet("test", pflag.ContinueOnError)
+	b = &Binder{OnConflict: ConflictDropShorthand}
+	if err := b.BindPFlags(f, server); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.BindPFlags(f, client, "client"); err != nil {
+		t.Fatal(err)
+	}
+	if peer := f.Lookup("client.peer"); peer == nil || peer.Shorthand != "" || f.ShorthandLookup("c").Name != "client.name" {
+		t.Fatal("only the conflicting shorthand should be dropped")
+	}
+	var conflict *ConflictError
+	if err := b.BindPFlags(f, client, "client"); !errors.As(err, &conflict) {
+		t.Fatalf("a name conflict should still fail, got %v", err)
+	}
+}
+
+func TestBindFlagsConflict(t *testing.T) {
+	f := flag.NewFlagSet("test", flag.ContinueOnError)
+	f.String("name", "", "")
+	var conflict *ConflictError
+	err := BindFlags(f, &struct {
+		Name string `flag:"name:name"`
+	}{})
+	if !errors.As(err, &conflict) || conflict.Flag != "name" {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestBindPFlagsLongShorthand(t *testing.T) {
+	var tagErr *TagError
+	err := BindPFlags(pflag.NewFlagSet("test", pflag.ContinueOnError), &struct {
+		Name string `flag:"name:name;shorthand:nm"`
+	}{})
+	if !errors.As(err, &tagErr) {
+		t.Fatalf("expected TagError, got %v", err)
+	}
+}
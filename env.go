@@ -0,0 +1,93 @@
+package bindflags
+
+import (
+	"encoding/csv"
+	"flag"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// envFlagTag is optionally implemented by the result of GetFlagTag and GetPFlagTag to name an environment variable
+type envFlagTag interface {
+	GetEnv() string
+}
+
+// envUsage appends the environment variable name to the usage text shown by PrintDefaults
+func envUsage(usage, env string) string {
+	if env == "" {
+		return usage
+	}
+	if usage == "" {
+		return "[$" + env + "]"
+	}
+	return usage + " [$" + env + "]"
+}
+
+// setFromEnv fills v from the environment variable env if it is set, and reports whether it was; s is its value.
+// It is called before the flag is registered, so the value is the default of the flag and a value given on the command line still wins.
+func setFromEnv(v flag.Value, target reflect.Value, env string) (s string, ok bool, err error) {
+	if env == "" {
+		return "", false, nil
+	}
+	if s, ok = os.LookupEnv(env); !ok {
+		return "", false, nil
+	}
+	if err = setValue(v, target, s); err != nil {
+		return s, false, err
+	}
+	return s, true, nil
+}
+
+// replacer is implemented by the values of bindflags that merge a second Set into the first, like maps:
+// replace sets s without marking the value as changed
+type replacer interface {
+	replace(s string) error
+}
+
+// setValue sets v from s without marking a slice or a map as changed, so that a later command-line value
+// replaces it instead of appending to it or merging into it. target is the bound field, a map field bound
+// with the StringTo flags of pflag is set through it since pflag has no way to replace the content of its map values.
+func setValue(v flag.Value, target reflect.Value, s string) error {
+	if sv, ok := v.(pflag.SliceValue); ok {
+		items, err := readAsCSV(s)
+		if err != nil {
+			return err
+		}
+		return sv.Replace(items)
+	}
+	if r, ok := v.(replacer); ok {
+		return r.replace(s)
+	}
+	if isPFlagMap(target) {
+		m, err := parseMap(target.Type(), s)
+		if err != nil {
+			return err
+		}
+		target.Set(m)
+		return nil
+	}
+	return v.Set(s)
+}
+
+// isPFlagMap reports whether target is a field of a type registerPFlag binds with a StringTo flag of pflag,
+// which merges every Set after the first one
+func isPFlagMap(target reflect.Value) bool {
+	if !target.CanAddr() {
+		return false
+	}
+	switch target.Addr().Interface().(type) {
+	case *map[string]string, *map[string]int, *map[string]int64:
+		return true
+	}
+	return false
+}
+
+func readAsCSV(s string) ([]string, error) {
+	if s == "" {
+		return []string{}, nil
+	}
+	return csv.NewReader(strings.NewReader(s)).Read()
+}
@@ -0,0 +1,117 @@
+package bindflags
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// TagError reports a tag that cannot be parsed, or whose value is not a valid default for the field
+type TagError struct {
+	// Struct is the type of the struct containing the field
+	Struct reflect.Type
+	// Field is the name of the struct field
+	Field string
+	// Tag is the text of the tag
+	Tag string
+	Err error
+}
+
+func (e *TagError) Error() string {
+	return fmt.Sprintf("bindflags: invalid tag %q of field %s.%s: %v", e.Tag, e.Struct, e.Field, e.Err)
+}
+
+func (e *TagError) Unwrap() error {
+	return e.Err
+}
+
+// UnsupportedTypeError reports a field whose type cannot be bound to a flag
+type UnsupportedTypeError struct {
+	// Struct is the type of the struct containing the field
+	Struct reflect.Type
+	// Field is the name of the struct field
+	Field string
+	// Tag is the text of the tag
+	Tag string
+	// Type is the type of the field
+	Type reflect.Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	return fmt.Sprintf("bindflags: unsupported type %s of field %s.%s", e.Type, e.Struct, e.Field)
+}
+
+// EnvError reports an environment variable whose value cannot be set on the flag of a field
+type EnvError struct {
+	// Struct is the type of the struct containing the field
+	Struct reflect.Type
+	// Field is the name of the struct field
+	Field string
+	// Flag is the dotted flag name of the field
+	Flag string
+	// Env is the name of the environment variable
+	Env string
+	// Value is the value of the environment variable
+	Value string
+	Err   error
+}
+
+func (e *EnvError) Error() string {
+	return fmt.Sprintf("bindflags: invalid value %q for flag %s of field %s.%s from env %s: %v", e.Value, e.Flag, e.Struct, e.Field, e.Env, e.Err)
+}
+
+func (e *EnvError) Unwrap() error {
+	return e.Err
+}
+
+// BindErrors is returned by a Binder with CollectErrors set, it lists every error found in the struct tree
+type BindErrors []error
+
+func (e BindErrors) Error() string {
+	s := make([]string, len(e))
+	for i, err := range e {
+		s[i] = err.Error()
+	}
+	return strings.Join(s, "\n")
+}
+
+// Unwrap lets errors.Is and errors.As look at every error
+func (e BindErrors) Unwrap() []error {
+	return e
+}
+
+// Is reports whether one of the errors matches target, for errors.Is before Go 1.20, which does not call Unwrap() []error
+func (e BindErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the errors that matches target, for errors.As before Go 1.20
+func (e BindErrors) As(target any) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// append adds err to e, the errors of a nested BindErrors are added one by one
+func (e BindErrors) append(err error) BindErrors {
+	if errs, ok := err.(BindErrors); ok {
+		return append(e, errs...)
+	}
+	return append(e, err)
+}
+
+func (e BindErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
@@ -1,11 +1,9 @@
 package bindflags
 
 import (
-	"errors"
 	"flag"
-	"fmt"
 	"reflect"
-	"strings"
+	"time"
 )
 
 type IFlagTag interface {
@@ -18,83 +16,14 @@ type GetFlagTag interface {
 	GetFlagTag() IFlagTag
 }
 
+// BindFlags binds the struct member fields to the standard library FlagSet, the tag is declared like BindPFlags without shorthand
 func BindFlags(f *flag.FlagSet, a any, group ...string) error {
-	rv := reflect.ValueOf(a)
-	if rv.Kind() != reflect.Ptr {
-		return errors.New("a must be a pointer")
-	}
-	rv = rv.Elem()
-	if rv.Kind() != reflect.Struct {
-		return errors.New("a must be a struct")
-	}
-	rt := rv.Type()
-	for i := 0; i < rt.NumField(); i++ {
-		if !rt.Field(i).IsExported() {
-			continue
-		}
-		ft := rt.Field(i)
-		tag := ft.Tag.Get(TagName)
-		if tag == "-" {
-			continue
-		}
-		fv := rv.Field(i)
-		if fv.Kind() == reflect.Ptr {
-			if fv.IsNil() {
-				fv.Set(reflect.New(fv.Type().Elem()))
-			}
-			fv = fv.Elem()
-		}
-		var flagTag = new(FlagTag)
-		var err error
-		if tag == "" {
-			if ft.Type.Implements(reflect.TypeOf((*GetFlagTag)(nil)).Elem()) {
-				result := rv.Field(i).Interface().(GetFlagTag).GetFlagTag()
-				flagTag.Name = result.GetName()
-				flagTag.Usage = result.GetUsage()
-				flagTag.Value = result.GetValue()
-			} else if ft.Type.Kind() != reflect.Struct {
-				continue
-			}
-		} else {
-			flagTag, err = scanFlagTag(tag)
-			if err != nil {
-				return err
-			}
-		}
-		if flagTag.Name == "" && rv.Kind() != reflect.Struct {
-			return fmt.Errorf("flag '%s' is required", flagTag.Name)
-		}
-		groupName := flagTag.Name
-		if flagTag.Name != "" {
-			flagTag.Name = strings.Join(append(group, flagTag.Name), ".")
-		}
-		switch fv.Kind() {
-		case reflect.Struct:
-			if groupName != "" {
-				group = append(group, groupName)
-			}
-			if err = BindFlags(f, fv.Addr().Interface(), group...); err != nil {
-				return err
-			}
-		case reflect.String:
-			f.StringVar((*string)(fv.Addr().UnsafePointer()), flagTag.Name, flagTag.Value, flagTag.Usage)
-		case reflect.Int:
-			f.IntVar((*int)(fv.Addr().UnsafePointer()), flagTag.Name, convertValue(flagTag.Name, flagTag.Value, "int").(int), flagTag.Usage)
-		case reflect.Int64:
-			f.Int64Var((*int64)(fv.Addr().UnsafePointer()), flagTag.Name, convertValue(flagTag.Name, flagTag.Value, "int64").(int64), flagTag.Usage)
-		case reflect.Uint:
-			f.UintVar((*uint)(fv.Addr().UnsafePointer()), flagTag.Name, convertValue(flagTag.Name, flagTag.Value, "uint").(uint), flagTag.Usage)
-		case reflect.Uint64:
-			f.Uint64Var((*uint64)(fv.Addr().UnsafePointer()), flagTag.Name, convertValue(flagTag.Name, flagTag.Value, "uint64").(uint64), flagTag.Usage)
-		case reflect.Float64:
-			f.Float64Var((*float64)(fv.Addr().UnsafePointer()), flagTag.Name, convertValue(flagTag.Name, flagTag.Value, "float64").(float64), flagTag.Usage)
-		case reflect.Bool:
-			f.BoolVar((*bool)(fv.Addr().UnsafePointer()), flagTag.Name, convertValue(flagTag.Name, flagTag.Value, "bool").(bool), flagTag.Usage)
-		default:
-			panic(fmt.Sprintf("unsupported type: %T", fv.Interface()))
-		}
-	}
-	return nil
+	return new(Binder).BindFlags(f, a, group...)
+}
+
+// BindFlags is like the package level BindFlags, using the options of the Binder
+func (b *Binder) BindFlags(f *flag.FlagSet, a any, group ...string) error {
+	return b.Bind(&flagRegistrar{flags: f}, a, group...)
 }
 
 func MustBindFlags(f *flag.FlagSet, a any, group ...string) {
@@ -103,3 +32,64 @@ func MustBindFlags(f *flag.FlagSet, a any, group ...string) {
 		panic(err)
 	}
 }
+
+// flagRegistrar is the Registrar of a flag.FlagSet
+type flagRegistrar struct {
+	flags *flag.FlagSet
+}
+
+func (r *flagRegistrar) Shorthands() bool {
+	return false
+}
+
+func (r *flagRegistrar) Lookup(name string) flag.Value {
+	if f := r.flags.Lookup(name); f != nil {
+		return f.Value
+	}
+	return nil
+}
+
+func (r *flagRegistrar) LookupShorthand(shorthand string) (string, flag.Value) {
+	return "", nil
+}
+
+func (r *flagRegistrar) Changed(name string) (set bool) {
+	r.flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+func (r *flagRegistrar) Register(f *Flag) (flag.Value, error) {
+	fs := r.flags
+	if !f.native || !isPlainType(f.Field.Type()) {
+		fs.Var(f.Value, f.Name, f.Usage)
+		return fs.Lookup(f.Name).Value, nil
+	}
+	p := f.Field.Addr().UnsafePointer()
+	switch f.Field.Kind() {
+	case reflect.String:
+		fs.StringVar((*string)(p), f.Name, *(*string)(p), f.Usage)
+	case reflect.Int:
+		fs.IntVar((*int)(p), f.Name, *(*int)(p), f.Usage)
+	case reflect.Int64:
+		if f.Field.Type() == durationType {
+			fs.DurationVar((*time.Duration)(p), f.Name, *(*time.Duration)(p), f.Usage)
+		} else {
+			fs.Int64Var((*int64)(p), f.Name, *(*int64)(p), f.Usage)
+		}
+	case reflect.Uint:
+		fs.UintVar((*uint)(p), f.Name, *(*uint)(p), f.Usage)
+	case reflect.Uint64:
+		fs.Uint64Var((*uint64)(p), f.Name, *(*uint64)(p), f.Usage)
+	case reflect.Float64:
+		fs.Float64Var((*float64)(p), f.Name, *(*float64)(p), f.Usage)
+	case reflect.Bool:
+		fs.BoolVar((*bool)(p), f.Name, *(*bool)(p), f.Usage)
+	default:
+		fs.Var(f.Value, f.Name, f.Usage)
+	}
+	return fs.Lookup(f.Name).Value, nil
+}
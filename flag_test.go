@@ -1,9 +1,13 @@
 package bindflags
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
 )
 
 type tf int
@@ -37,3 +41,154 @@ func TestBindFlags(t *testing.T) {
 	f.PrintDefaults()
 	fmt.Printf("%#v\n", e)
 }
+
+func TestBindFlagsEnv(t *testing.T) {
+	t.Setenv("TEST_BIND_B", "456")
+	f := flag.NewFlagSet("test", flag.ContinueOnError)
+	e := &struct {
+		A string `flag:"name:a;value:abc;env:TEST_BIND_A"`
+		B int    `flag:"name:b;value:123;env:TEST_BIND_B"`
+	}{}
+	if err := BindFlags(f, e); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if e.A != "abc" || e.B != 456 {
+		t.Fatalf("unexpected values: %#v", e)
+	}
+}
+
+func TestBindFlagsErrors(t *testing.T) {
+	var tagErr *TagError
+	err := BindFlags(flag.NewFlagSet("test", flag.ContinueOnError), &struct {
+		On bool `flag:"name:on;value:maybe"`
+	}{})
+	if !errors.As(err, &tagErr) || tagErr.Field != "On" {
+		t.Fatalf("expected TagError, got %v", err)
+	}
+	var typeErr *UnsupportedTypeError
+	err = BindFlags(flag.NewFlagSet("test", flag.ContinueOnError), &struct {
+		Ports chan int `flag:"name:ports"`
+	}{})
+	if !errors.As(err, &typeErr) || typeErr.Field != "Ports" {
+		t.Fatalf("expected UnsupportedTypeError, got %v", err)
+	}
+}
+
+func TestBindFlagsTime(t *testing.T) {
+	f := flag.NewFlagSet("test", flag.ContinueOnError)
+	c := &struct {
+		Timeout time.Duration `flag:"name:timeout;value:30s"`
+		Until   time.Time     `flag:"name:until;layout:2006-01-02;value:2024-12-31"`
+	}{}
+	MustBindFlags(f, c)
+	if c.Timeout != 30*time.Second || c.Until.Year() != 2024 {
+		t.Fatalf("unexpected defaults: %#v", c)
+	}
+	if err := f.Parse([]string{"-timeout", "1h", "-until", "2025-01-01"}); err != nil {
+		t.Fatal(err)
+	}
+	if c.Timeout != time.Hour || c.Until.Year() != 2025 {
+		t.Fatalf("unexpected values: %#v", c)
+	}
+}
+
+type smallConfig struct {
+	Level   int8               `flag:"name:level;value:-1"`
+	Port    uint16             `flag:"name:port;value:8080;max:9000"`
+	Ratio   float32            `flag:"name:ratio;value:0.5"`
+	Tags    []string           `flag:"name:tags;value:[\"a\"]"`
+	Ports   []int              `flag:"name:ports"`
+	Waits   []time.Duration    `flag:"name:waits;value:[\"1s\"]"`
+	Pair    [2]int32           `flag:"name:pair"`
+	Weights map[string]float32 `flag:"name:weights;value:a=1.5"`
+}
+
+func TestBindFlagsTypes(t *testing.T) {
+	f := flag.NewFlagSet("test", flag.ContinueOnError)
+	c := new(smallConfig)
+	MustBindFlags(f, c)
+	if c.Level != -1 || c.Port != 8080 || c.Ratio != 0.5 || len(c.Tags) != 1 || c.Waits[0] != time.Second || c.Weights["a"] != 1.5 {
+		t.Fatalf("unexpected defaults: %#v", c)
+	}
+	err := f.Parse([]string{"-level", "3", "-port", "9000", "-ratio", "0.25", "-tags", "x,y", "-tags", "z",
+		"-ports", "80,443", "-waits", "2m", "-pair", "1,2", "-weights", "b=2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Level != 3 || c.Port != 9000 || c.Ratio != 0.25 || len(c.Tags) != 3 || c.Tags[2] != "z" {
+		t.Fatalf("unexpected values: %#v", c)
+	}
+	if len(c.Ports) != 2 || c.Ports[1] != 443 || len(c.Waits) != 1 || c.Waits[0] != 2*time.Minute || c.Pair != [2]int32{1, 2} {
+		t.Fatalf("unexpected values: %#v", c)
+	}
+	if err = Validate(c); err != nil {
+		t.Fatal(err)
+	}
+	if err = f.Set("level", "200"); err == nil {
+		t.Fatal("expected an out of range error")
+	}
+
+	p := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	MustBindPFlags(p, new(smallConfig))
+	f.VisitAll(func(fl *flag.Flag) {
+		if pf := p.Lookup(fl.Name); pf == nil {
+			t.Errorf("flag %s is not bound by BindPFlags", fl.Name)
+		}
+	})
+}
+
+type sharedConfig struct {
+	Name string `flag:"Name:name;shorthand:n;value:ss;usage:name of student"`
+	Age  int    `flag:"name:age;shorthand:a;value:18;usage:age of student"`
+	Sex  bool   `flag:"name:sex;shorthand:s;value:true;persistent"`
+}
+
+func TestBindFlagsShorthand(t *testing.T) {
+	f := flag.NewFlagSet("test", flag.ContinueOnError)
+	fc := new(sharedConfig)
+	if err := BindFlags(f, fc); err != nil {
+		t.Fatal(err)
+	}
+	p := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	pc := new(sharedConfig)
+	if err := BindPFlags(p, pc); err != nil {
+		t.Fatal(err)
+	}
+	if *fc != *pc || fc.Name != "ss" || fc.Age != 18 || !fc.Sex {
+		t.Fatalf("unexpected defaults: %#v %#v", fc, pc)
+	}
+	if f.Lookup("a") != nil || p.ShorthandLookup("a") == nil {
+		t.Fatal("the shorthand should only be bound by BindPFlags")
+	}
+	if err := f.Parse([]string{"-age", "20"}); err != nil || fc.Age != 20 {
+		t.Fatalf("unexpected age %d: %v", fc.Age, err)
+	}
+}
+
+func TestBindFlagsSliceJSON(t *testing.T) {
+	type config struct {
+		Addrs []string `flag:"name:addrs"`
+		Sizes []int    `flag:"name:sizes"`
+	}
+	f := flag.NewFlagSet("test", flag.ContinueOnError)
+	c := new(config)
+	MustBindFlags(f, c)
+	if err := f.Parse([]string{"-addrs", "[::1]:80", "-sizes", "[1,2]", "-sizes", "3"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Addrs) != 1 || c.Addrs[0] != "[::1]:80" {
+		t.Fatalf("a value that is not a JSON array should be read as a,b,c: %q", c.Addrs)
+	}
+	if len(c.Sizes) != 3 || c.Sizes[2] != 3 {
+		t.Fatalf("a value after a JSON array should be appended: %v", c.Sizes)
+	}
+	p := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	pc := new(config)
+	MustBindPFlags(p, pc)
+	if err := p.Parse([]string{"--addrs", "[::1]:80"}); err != nil || len(pc.Addrs) != 1 || pc.Addrs[0] != "[::1]:80" {
+		t.Fatalf("unexpected addrs %q: %v", pc.Addrs, err)
+	}
+}
@@ -4,6 +4,11 @@ type FlagTag struct {
 	Name  string
 	Value string
 	Usage string
+	Env   string
+	// Encoding is the encoding of a []byte field: hex, the default, or base64
+	Encoding string
+	// Layout is the time.Parse layout of a time.Time field, time.RFC3339 by default
+	Layout string
 }
 
 func (f *FlagTag) GetName() string {
@@ -15,3 +20,12 @@ func (f *FlagTag) GetValue() string {
 func (f *FlagTag) GetUsage() string {
 	return f.Usage
 }
+func (f *FlagTag) GetEnv() string {
+	return f.Env
+}
+func (f *FlagTag) GetEncoding() string {
+	return f.Encoding
+}
+func (f *FlagTag) GetLayout() string {
+	return f.Layout
+}
@@ -2,4 +2,11 @@ module github.com/Li-giegie/bindflags
 
 go 1.18
 
-require github.com/spf13/pflag v1.0.6
+require (
+	github.com/BurntSushi/toml v1.4.0
+	github.com/spf13/cobra v1.9.1
+	github.com/spf13/pflag v1.0.6
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require github.com/inconshreveable/mousetrap v1.1.0 // indirect
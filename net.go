@@ -0,0 +1,127 @@
+package bindflags
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+
+	"github.com/spf13/pflag"
+)
+
+var (
+	ipType      = reflect.TypeOf(net.IP{})
+	ipNetType   = reflect.TypeOf(net.IPNet{})
+	ipMaskType  = reflect.TypeOf(net.IPMask{})
+	ipSliceType = reflect.TypeOf([]net.IP{})
+)
+
+// pflagNetTypes are the types bound with the IP flags of pflag
+var pflagNetTypes = map[reflect.Type]bool{ipType: true, ipNetType: true, ipMaskType: true, ipSliceType: true}
+
+// netValue returns a pflag.Value for the net types without text methods, nil for the other types
+func netValue(v reflect.Value) pflag.Value {
+	switch p := v.Addr().Interface().(type) {
+	case *net.IPNet:
+		return &ipNetValue{p}
+	case *net.IPMask:
+		return &ipMaskValue{p}
+	case *url.URL:
+		return &urlValue{p}
+	}
+	return nil
+}
+
+func parseIPNet(s string) (*net.IPNet, error) {
+	if s == "" {
+		return &net.IPNet{}, nil
+	}
+	_, n, err := net.ParseCIDR(s)
+	return n, err
+}
+
+func parseIPMask(s string) (net.IPMask, error) {
+	if s == "" {
+		return nil, nil
+	}
+	mask := pflag.ParseIPv4Mask(s)
+	if mask == nil {
+		return nil, fmt.Errorf("invalid IP mask %q", s)
+	}
+	return mask, nil
+}
+
+// ipNetValue is a pflag.Value for a net.IPNet, written in CIDR notation
+type ipNetValue struct {
+	p *net.IPNet
+}
+
+func (v *ipNetValue) Set(s string) error {
+	n, err := parseIPNet(s)
+	if err != nil {
+		return err
+	}
+	*v.p = *n
+	return nil
+}
+
+func (v *ipNetValue) String() string {
+	if v.p == nil || v.p.IP == nil {
+		return ""
+	}
+	return v.p.String()
+}
+
+func (v *ipNetValue) Type() string {
+	return "ipNet"
+}
+
+// ipMaskValue is a pflag.Value for a net.IPMask, written as 255.255.255.0 or ffffff00
+type ipMaskValue struct {
+	p *net.IPMask
+}
+
+func (v *ipMaskValue) Set(s string) error {
+	mask, err := parseIPMask(s)
+	if err != nil {
+		return err
+	}
+	*v.p = mask
+	return nil
+}
+
+func (v *ipMaskValue) String() string {
+	if v.p == nil || *v.p == nil {
+		return ""
+	}
+	return v.p.String()
+}
+
+func (v *ipMaskValue) Type() string {
+	return "ipMask"
+}
+
+// urlValue is a pflag.Value for a url.URL
+type urlValue struct {
+	p *url.URL
+}
+
+func (v *urlValue) Set(s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+	*v.p = *u
+	return nil
+}
+
+func (v *urlValue) String() string {
+	if v.p == nil {
+		return ""
+	}
+	return v.p.String()
+}
+
+func (v *urlValue) Type() string {
+	return "url"
+}
@@ -0,0 +1,99 @@
+package bindflags
+
+import (
+	"flag"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+type netConfig struct {
+	ListenIP  net.IP       `flag:"name:listen-ip;shorthand:l;value:127.0.0.1"`
+	AllowCIDR net.IPNet    `flag:"name:allow-cidr;value:10.0.0.0/8;required"`
+	Mask      net.IPMask   `flag:"name:mask;value:255.255.255.0"`
+	Peers     []net.IP     `flag:"name:peers;value:[\"10.0.0.1\"]"`
+	Addr      netip.Addr   `flag:"name:addr"`
+	Prefix    netip.Prefix `flag:"name:prefix;value:192.168.0.0/16"`
+	Endpoint  *url.URL     `flag:"name:endpoint;value:https://example.com/api"`
+	Target    net.IP       `arg:"0"`
+}
+
+func TestBindPFlagsNet(t *testing.T) {
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	c := new(netConfig)
+	MustBindPFlags(f, c)
+	if !c.ListenIP.Equal(net.IPv4(127, 0, 0, 1)) || c.AllowCIDR.String() != "10.0.0.0/8" || c.Mask.String() != "ffffff00" {
+		t.Fatalf("unexpected defaults: %#v", c)
+	}
+	if len(c.Peers) != 1 || c.Prefix.String() != "192.168.0.0/16" || c.Endpoint.Host != "example.com" {
+		t.Fatalf("unexpected defaults: %#v", c)
+	}
+	if typ := f.Lookup("allow-cidr").Value.Type(); typ != "ipNet" {
+		t.Fatalf("unexpected type %q", typ)
+	}
+	err := f.Parse([]string{"-l", "::1", "--allow-cidr", "172.16.0.0/12", "--mask", "ffff0000",
+		"--peers", "10.0.0.2,10.0.0.3", "--addr", "fe80::1", "--prefix", "10.1.0.0/16", "--endpoint", "http://localhost:8080", "192.0.2.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.ListenIP.String() != "::1" || c.AllowCIDR.String() != "172.16.0.0/12" || c.Mask.String() != "ffff0000" {
+		t.Fatalf("unexpected values: %#v", c)
+	}
+	if len(c.Peers) != 2 || c.Addr.String() != "fe80::1" || c.Prefix.String() != "10.1.0.0/16" || c.Endpoint.Port() != "8080" {
+		t.Fatalf("unexpected values: %#v", c)
+	}
+	if err = BindArgs(f.Args(), c); err != nil || c.Target.String() != "192.0.2.1" {
+		t.Fatalf("unexpected argument %v: %v", c.Target, err)
+	}
+	if err = Validate(c); err != nil {
+		t.Fatal(err)
+	}
+	if err = f.Set("listen-ip", "localhost"); err == nil {
+		t.Fatal("expected an invalid value error")
+	}
+}
+
+func TestBindPFlagsNetConfig(t *testing.T) {
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	c := new(netConfig)
+	b := new(Binder)
+	if err := b.BindPFlags(f, c); err != nil {
+		t.Fatal(err)
+	}
+	doc := `{"peers":["10.0.0.7","10.0.0.8"],"allow-cidr":"0.0.0.0/0","endpoint":"ftp://files"}`
+	if err := b.LoadConfig(strings.NewReader(doc)); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Peers) != 2 || c.AllowCIDR.String() != "0.0.0.0/0" || c.Endpoint.Scheme != "ftp" {
+		t.Fatalf("unexpected values: %#v", c)
+	}
+}
+
+func TestBindFlagsNet(t *testing.T) {
+	f := flag.NewFlagSet("test", flag.ContinueOnError)
+	c := &struct {
+		ListenIP  net.IP    `flag:"name:listen-ip;value:127.0.0.1"`
+		AllowCIDR net.IPNet `flag:"name:allow-cidr"`
+		Endpoint  url.URL   `flag:"name:endpoint"`
+	}{}
+	MustBindFlags(f, c)
+	if !c.ListenIP.Equal(net.IPv4(127, 0, 0, 1)) {
+		t.Fatalf("unexpected default %v", c.ListenIP)
+	}
+	if err := f.Parse([]string{"-listen-ip", "10.0.0.1", "-allow-cidr", "10.0.0.0/24", "-endpoint", "https://x.org"}); err != nil {
+		t.Fatal(err)
+	}
+	if c.ListenIP.String() != "10.0.0.1" || c.AllowCIDR.String() != "10.0.0.0/24" || c.Endpoint.Host != "x.org" {
+		t.Fatalf("unexpected values: %#v", c)
+	}
+	var usage strings.Builder
+	f.SetOutput(&usage)
+	f.PrintDefaults()
+	if !strings.Contains(usage.String(), `(default 127.0.0.1)`) {
+		t.Fatalf("unexpected usage %q", usage.String())
+	}
+}
@@ -0,0 +1,189 @@
+package bindflags
+
+import (
+	"reflect"
+
+	"github.com/spf13/pflag"
+)
+
+// Optional is a field type holding a value of type T and whether a flag, an environment variable or a config file set it.
+// It is bound like a field of type T, the tag value is its default and does not make it set.
+// Optional 持有一个 T 类型的值，并记录它是否由命令行、环境变量或配置文件设置
+type Optional[T any] struct {
+	value T
+	set   bool
+}
+
+// Some returns an Optional holding v, set
+func Some[T any](v T) Optional[T] {
+	return Optional[T]{value: v, set: true}
+}
+
+// Get returns the value, the default or the zero value when it is not set
+func (o Optional[T]) Get() T {
+	return o.value
+}
+
+// IsSet reports whether the value was given on the command line, in the environment or in a config file, or by Some
+func (o Optional[T]) IsSet() bool {
+	return o.set
+}
+
+// Or returns the value when it is set, def otherwise
+func (o Optional[T]) Or(def T) T {
+	if o.set {
+		return o.value
+	}
+	return def
+}
+
+func (o *Optional[T]) elem() reflect.Value {
+	return reflect.ValueOf(&o.value).Elem()
+}
+
+func (o *Optional[T]) markSet() {
+	o.set = true
+}
+
+func (o *Optional[T]) unset() {
+	o.set = false
+}
+
+// optional is implemented by *Optional[T] for every T
+type optional interface {
+	// elem returns the addressable value
+	elem() reflect.Value
+	markSet()
+	unset()
+}
+
+var optionalType = reflect.TypeOf((*optional)(nil)).Elem()
+
+// optionalElem returns the value held by v when v is an Optional, ok is false otherwise
+func optionalElem(v reflect.Value) (elem reflect.Value, ok bool) {
+	if !reflect.PointerTo(v.Type()).Implements(optionalType) {
+		return v, false
+	}
+	if !v.CanAddr() {
+		p := reflect.New(v.Type())
+		p.Elem().Set(v)
+		v = p.Elem()
+	}
+	return v.Addr().Interface().(optional).elem(), true
+}
+
+// optionalIsSet reports whether the Optional v is set, ok is false when v is not an Optional
+func optionalIsSet(v reflect.Value) (set, ok bool) {
+	if !reflect.PointerTo(v.Type()).Implements(optionalType) {
+		return false, false
+	}
+	return v.Interface().(interface{ IsSet() bool }).IsSet(), true
+}
+
+// unsetOptionals marks the Optional elements of the slice, array or map v as not set, they hold the tag default
+func unsetOptionals(v reflect.Value) {
+	if !v.IsValid() || !reflect.PointerTo(v.Type().Elem()).Implements(optionalType) {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			v.Index(i).Addr().Interface().(optional).unset()
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			elem := reflect.New(v.Type().Elem())
+			elem.Elem().Set(iter.Value())
+			elem.Interface().(optional).unset()
+			v.SetMapIndex(iter.Key(), elem.Elem())
+		}
+	}
+}
+
+// newOptionalValue returns the value of the Optional v, nil when v is not an Optional. The value is returned with
+// the error for a T that is not supported, a *optionalSliceValue when T is a slice or an array.
+func newOptionalValue(v reflect.Value, flagTag *PFlagTag) (pflag.Value, error) {
+	if !reflect.PointerTo(v.Type()).Implements(optionalType) {
+		return nil, nil
+	}
+	o := v.Addr().Interface().(optional)
+	value, native, err := newValue(o.elem(), flagTag)
+	ov := &optionalValue{opt: o, value: value, native: native}
+	if _, ok := value.(boolFlag); ok {
+		return &boolValue{ov}, err
+	}
+	if _, ok := value.(pflag.SliceValue); ok {
+		return &optionalSliceValue{ov}, err
+	}
+	return ov, err
+}
+
+// optionalValue sets an Optional through the value of its T, and marks it set
+type optionalValue struct {
+	opt    optional
+	value  pflag.Value
+	native bool
+}
+
+func (v *optionalValue) Set(s string) error {
+	if err := v.value.Set(s); err != nil {
+		return err
+	}
+	v.opt.markSet()
+	return nil
+}
+
+func (v *optionalValue) replace(s string) error {
+	r, ok := v.value.(replacer)
+	if !ok {
+		return v.Set(s)
+	}
+	if err := r.replace(s); err != nil {
+		return err
+	}
+	v.opt.markSet()
+	return nil
+}
+
+func (v *optionalValue) String() string {
+	if v == nil || v.value == nil {
+		return ""
+	}
+	return v.value.String()
+}
+
+func (v *optionalValue) Type() string {
+	return v.value.Type()
+}
+
+// setDefault sets the tag value s, the Optional is not set afterwards
+func (v *optionalValue) setDefault(s string) error {
+	v.opt.unset()
+	return setDefault(v.value, v.opt.elem(), s, v.native)
+}
+
+// optionalSliceValue is the optionalValue of an Optional of a slice or an array
+type optionalSliceValue struct {
+	*optionalValue
+}
+
+func (v *optionalSliceValue) Append(s string) error {
+	if err := v.value.(pflag.SliceValue).Append(s); err != nil {
+		return err
+	}
+	v.opt.markSet()
+	return nil
+}
+
+func (v *optionalSliceValue) Replace(items []string) error {
+	if err := v.value.(pflag.SliceValue).Replace(items); err != nil {
+		return err
+	}
+	v.opt.markSet()
+	return nil
+}
+
+func (v *optionalSliceValue) GetSlice() []string {
+	return v.value.(pflag.SliceValue).GetSlice()
+}
@@ -0,0 +1,147 @@
+package bindflags
+
+import (
+	"flag"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+type optionalConfig struct {
+	Port    Optional[int]           `flag:"name:port;value:8080;max:9000"`
+	Verbose Optional[bool]          `flag:"name:verbose;shorthand:v"`
+	Tags    Optional[[]string]      `flag:"name:tags;env:TEST_OPTIONAL_TAGS"`
+	Timeout Optional[time.Duration] `flag:"name:timeout;value:30s"`
+	Retries []Optional[int]         `flag:"name:retries"`
+	DB      struct {
+		Host Optional[string] `flag:"name:host;value:localhost"`
+	} `flag:"db"`
+}
+
+func TestOptional(t *testing.T) {
+	t.Setenv("TEST_OPTIONAL_TAGS", "a,b")
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	c := new(optionalConfig)
+	b := new(Binder)
+	if err := b.BindPFlags(f, c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Port.IsSet() || c.Port.Get() != 8080 || c.Timeout.Get() != 30*time.Second || c.DB.Host.IsSet() || c.DB.Host.Get() != "localhost" {
+		t.Fatalf("unexpected defaults: %#v", c)
+	}
+	if !c.Tags.IsSet() || len(c.Tags.Get()) != 2 {
+		t.Fatalf("the env should set the tags: %#v", c.Tags)
+	}
+	for name, want := range map[string][2]string{"port": {"int", "8080"}, "timeout": {"duration", "30s"}, "tags": {"stringSlice", "[a,b]"}, "retries": {"intSlice", ""}} {
+		fl := f.Lookup(name)
+		if fl.Value.Type() != want[0] || fl.DefValue != want[1] {
+			t.Errorf("unexpected flag %s: %s %q", name, fl.Value.Type(), fl.DefValue)
+		}
+	}
+	if err := f.Parse([]string{"-v", "--tags", "c", "--retries", "1,2", "--port", "9001"}); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Verbose.IsSet() || !c.Verbose.Get() || !c.Port.IsSet() || c.Port.Get() != 9001 || c.Timeout.IsSet() {
+		t.Fatalf("unexpected values: %#v", c)
+	}
+	if tags := c.Tags.Get(); len(tags) != 1 || tags[0] != "c" {
+		t.Fatalf("the command line should replace the env value: %v", tags)
+	}
+	if len(c.Retries) != 2 || !c.Retries[1].IsSet() || c.Retries[1].Get() != 2 {
+		t.Fatalf("unexpected retries: %#v", c.Retries)
+	}
+	if err := Validate(c); err == nil {
+		t.Fatal("expected a max error")
+	}
+	if err := b.LoadConfig(strings.NewReader(`{"db":{"host":"db.local"},"timeout":"1m"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if !c.DB.Host.IsSet() || c.DB.Host.Get() != "db.local" || c.Timeout.Or(0) != time.Minute {
+		t.Fatalf("the config file should set the values: %#v", c)
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	sc := &struct {
+		Debug Optional[bool]    `flag:"name:debug"`
+		Level Optional[float64] `flag:"name:level;value:0.5"`
+	}{Debug: Some(false)}
+	if err := (&Binder{KeepValues: true}).BindFlags(fs, sc); err != nil {
+		t.Fatal(err)
+	}
+	if !sc.Debug.IsSet() || sc.Level.IsSet() || sc.Level.Or(1) != 1 || fs.Lookup("level").DefValue != "0.5" {
+		t.Fatalf("unexpected defaults: %#v", sc)
+	}
+	if err := fs.Parse([]string{"-debug", "-level", "2"}); err != nil {
+		t.Fatal(err)
+	}
+	if !sc.Debug.Get() || sc.Level.Or(1) != 2 {
+		t.Fatalf("unexpected values: %#v", sc)
+	}
+}
+
+func TestOptionalReset(t *testing.T) {
+	c := &struct {
+		Port Optional[int] `flag:"name:port"`
+	}{Port: Some(80)}
+	MustBindPFlags(pflag.NewFlagSet("test", pflag.ContinueOnError), c)
+	if c.Port.IsSet() || c.Port.Get() != 0 {
+		t.Fatalf("binding without KeepValues should reset the field: %#v", c.Port)
+	}
+}
+
+func TestOptionalSliceDefault(t *testing.T) {
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	c := &struct {
+		Retries []Optional[int]          `flag:"name:retries;value:[1,2]"`
+		Limits  map[string]Optional[int] `flag:"name:limits;value:a=1"`
+	}{}
+	MustBindPFlags(f, c)
+	if len(c.Retries) != 2 || c.Retries[0].IsSet() || c.Retries[1].IsSet() || c.Retries[1].Get() != 2 {
+		t.Fatalf("tag defaults should not be set: %#v", c.Retries)
+	}
+	if c.Limits["a"].IsSet() || c.Limits["a"].Get() != 1 {
+		t.Fatalf("tag defaults should not be set: %#v", c.Limits)
+	}
+	if def := f.Lookup("retries").DefValue; def != "[1,2]" {
+		t.Fatalf("unexpected default %q", def)
+	}
+	if def := f.Lookup("limits").DefValue; def != "[a=1]" {
+		t.Fatalf("unexpected default %q", def)
+	}
+	var buf strings.Builder
+	p := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	p.SetOutput(&buf)
+	MustBindPFlags(p, &struct {
+		Tags Optional[[]string] `flag:"name:tags"`
+		Ids  []Optional[int]    `flag:"name:ids"`
+	}{})
+	p.PrintDefaults()
+	if out := buf.String(); strings.Contains(out, "(default") {
+		t.Fatalf("no default should be shown:\n%s", out)
+	}
+	if err := f.Parse([]string{"--retries", "3"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Retries) != 1 || !c.Retries[0].IsSet() || c.Retries[0].Get() != 3 {
+		t.Fatalf("unexpected values: %#v", c.Retries)
+	}
+}
+
+func TestValidateOptionalRequired(t *testing.T) {
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	c := &struct {
+		Port Optional[int] `flag:"name:port;value:8080;required"`
+	}{}
+	MustBindPFlags(f, c)
+	if err := Validate(c); err == nil {
+		t.Fatal("expected a required error for an Optional that is not set")
+	}
+	if err := f.Parse([]string{"--port", "0"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Validate(c); err != nil {
+		t.Fatalf("an Optional set to 0 should satisfy required: %v", err)
+	}
+}
@@ -1,11 +1,10 @@
 package bindflags
 
 import (
-	"errors"
-	"fmt"
+	"flag"
 	"github.com/spf13/pflag"
+	"net"
 	"reflect"
-	"strings"
 	"time"
 )
 
@@ -27,217 +26,227 @@ type GetPFlagTag interface {
 // No key: Name string 'flag:"name; n; ss; name of student"`
 // Blend mode: "Name string 'flag:"Name:name; n; ss; name of student"`”
 // Or define a custom type, and then implement the GetFlagTag interface for the type
+// The optional env key names an environment variable that fills the field when the flag is not given: flag > env > value
 // BindFlags 把结构体成员字段绑定到cobra FlagSet中，结构体入参必须是指针类型；在字段的tag加上声明如
 // 键值对：Name  string `flag:"Name:name;shorthand:n;value:ss;usage:name of student"`
 // 无键值：Name  string `flag:"name;n;ss;name of student"`
 // 混合模式： “Name  string `flag:"Name:name;n;ss;name of student"`”
 // 再或者 定义一个自定义类型，然后给类型实现 GetFlagTag 接口
+// 可选的 env 键指定环境变量，命令行未给出该标志时从环境变量取值，优先级：命令行 > 环境变量 > value
 func BindPFlags(flag *pflag.FlagSet, a any, group ...string) error {
-	rv := reflect.ValueOf(a)
-	if rv.Kind() != reflect.Ptr {
-		return errors.New("a must be a pointer")
+	return new(Binder).BindPFlags(flag, a, group...)
+}
+
+// BindPFlags is like the package level BindPFlags, using the options of the Binder
+func (b *Binder) BindPFlags(flag *pflag.FlagSet, a any, group ...string) error {
+	return b.Bind(&pflagRegistrar{flags: flag}, a, group...)
+}
+
+func MustBindPFlags(flag *pflag.FlagSet, a any) {
+	err := BindPFlags(flag, a)
+	if err != nil {
+		panic(err)
 	}
-	rv = rv.Elem()
-	if rv.Kind() != reflect.Struct {
-		return errors.New("a must be a struct")
+}
+
+// pflagRegistrar is the Registrar of a pflag.FlagSet, persistent receives the flags marked persistent when it is set
+type pflagRegistrar struct {
+	flags      *pflag.FlagSet
+	persistent *pflag.FlagSet
+	// inherited are the persistent flags of the parent commands, a name or shorthand used there is a conflict too
+	inherited []*pflag.FlagSet
+}
+
+func (r *pflagRegistrar) Shorthands() bool {
+	return true
+}
+
+func (r *pflagRegistrar) lookup(name string) *pflag.Flag {
+	if f := r.flags.Lookup(name); f != nil {
+		return f
 	}
-	rt := rv.Type()
-	for i := 0; i < rv.NumField(); i++ {
-		if !rt.Field(i).IsExported() {
-			continue
+	if r.persistent != nil {
+		if f := r.persistent.Lookup(name); f != nil {
+			return f
 		}
-		ft := rt.Field(i)
-		tag := ft.Tag.Get(TagName)
-		if tag == "-" {
-			continue
-		}
-		fv := rv.Field(i)
-		if fv.Kind() == reflect.Ptr {
-			if fv.IsNil() {
-				fv.Set(reflect.New(fv.Type().Elem()))
-			}
-			fv = fv.Elem()
+	}
+	for _, fs := range r.inherited {
+		if f := fs.Lookup(name); f != nil {
+			return f
 		}
-		var flagTag = new(PFlagTag)
-		var err error
-		if tag == "" {
-			if ft.Type.Implements(reflect.TypeOf((*GetPFlagTag)(nil)).Elem()) {
-				result := rv.Field(i).Interface().(GetPFlagTag).GetPFlagTag()
-				flagTag.Name = result.GetName()
-				flagTag.Usage = result.GetUsage()
-				flagTag.Value = result.GetValue()
-				flagTag.Shorthand = result.GetShorthand()
-			} else if ft.Type.Kind() != reflect.Struct {
-				continue
-			}
-		} else {
-			flagTag, err = scanPFlagTag(tag)
-			if err != nil {
-				return err
-			}
+	}
+	return nil
+}
+
+func (r *pflagRegistrar) Lookup(name string) flag.Value {
+	if f := r.lookup(name); f != nil {
+		return f.Value
+	}
+	return nil
+}
+
+func (r *pflagRegistrar) LookupShorthand(shorthand string) (string, flag.Value) {
+	f := r.flags.ShorthandLookup(shorthand)
+	if f == nil && r.persistent != nil {
+		f = r.persistent.ShorthandLookup(shorthand)
+	}
+	for _, fs := range r.inherited {
+		if f != nil {
+			break
 		}
-		if flagTag.Name == "" && rv.Kind() != reflect.Struct {
-			return fmt.Errorf("flag '%s' is required", flagTag.Name)
+		f = fs.ShorthandLookup(shorthand)
+	}
+	if f == nil {
+		return "", nil
+	}
+	return f.Name, f.Value
+}
+
+func (r *pflagRegistrar) Changed(name string) bool {
+	f := r.lookup(name)
+	return f != nil && f.Changed
+}
+
+func (r *pflagRegistrar) Register(f *Flag) (flag.Value, error) {
+	fs := r.flags
+	if f.Tag.Persistent && r.persistent != nil {
+		fs = r.persistent
+	}
+	if !f.native || !registerPFlag(fs, f) {
+		fs.VarP(f.Value, f.Name, f.Shorthand, f.Usage)
+		if b, ok := f.Value.(boolFlag); ok && b.IsBoolFlag() {
+			fs.Lookup(f.Name).NoOptDefVal = "true"
 		}
-		groupName := flagTag.Name
-		if flagTag.Name != "" {
-			flagTag.Name = strings.Join(append(group, flagTag.Name), ".")
+	}
+	return fs.Lookup(f.Name).Value, nil
+}
+
+// registerPFlag registers f with the flag function of pflag for its type, the default is the value of the field.
+// It reports false when pflag has no function for the type.
+func registerPFlag(fs *pflag.FlagSet, f *Flag) bool {
+	switch p := f.Field.Addr().Interface().(type) {
+	case *net.IP:
+		fs.IPVarP(p, f.Name, f.Shorthand, *p, f.Usage)
+		return true
+	case *net.IPNet:
+		fs.IPNetVarP(p, f.Name, f.Shorthand, *p, f.Usage)
+		return true
+	case *net.IPMask:
+		fs.IPMaskVarP(p, f.Name, f.Shorthand, *p, f.Usage)
+		return true
+	case *[]net.IP:
+		fs.IPSliceVarP(p, f.Name, f.Shorthand, *p, f.Usage)
+		return true
+	case *map[string]string:
+		fs.StringToStringVarP(p, f.Name, f.Shorthand, *p, f.Usage)
+		return true
+	case *map[string]int:
+		fs.StringToIntVarP(p, f.Name, f.Shorthand, *p, f.Usage)
+		return true
+	case *map[string]int64:
+		fs.StringToInt64VarP(p, f.Name, f.Shorthand, *p, f.Usage)
+		return true
+	}
+	if isBytesType(f.Field.Type()) {
+		p := (*[]byte)(f.Field.Addr().UnsafePointer())
+		if f.Tag.Encoding == "base64" {
+			fs.BytesBase64VarP(p, f.Name, f.Shorthand, *p, f.Usage)
+		} else {
+			fs.BytesHexVarP(p, f.Name, f.Shorthand, *p, f.Usage)
 		}
-		switch fv.Kind() {
-		case reflect.Struct:
-			if groupName != "" {
-				group = append(group, groupName)
-			}
-			if err = BindPFlags(flag, fv.Addr().Interface(), group...); err != nil {
-				return err
-			}
-		case reflect.Slice:
-			switch fv.Type().Elem().Kind() {
-			case reflect.String:
-				if flagTag.Shorthand != "" && flagTag.Shorthand != "-" {
-					flag.StringSliceVarP((*[]string)(fv.Addr().UnsafePointer()), flagTag.Name, flagTag.Shorthand, convertValue(flagTag.Name, flagTag.Value, "string", true).([]string), flagTag.Usage)
-				} else {
-					flag.StringSliceVar((*[]string)(fv.Addr().UnsafePointer()), flagTag.Name, convertValue(flagTag.Name, flagTag.Value, "string", true).([]string), flagTag.Usage)
-				}
-			case reflect.Int:
-				if flagTag.Shorthand != "" && flagTag.Shorthand != "-" {
-					flag.IntSliceVarP((*[]int)(fv.Addr().UnsafePointer()), flagTag.Name, flagTag.Shorthand, convertValue(flagTag.Name, flagTag.Value, "int", true).([]int), flagTag.Usage)
-				} else {
-					flag.IntSliceVar((*[]int)(fv.Addr().UnsafePointer()), flagTag.Name, convertValue(flagTag.Name, flagTag.Value, "int", true).([]int), flagTag.Usage)
-				}
-			case reflect.Int32:
-				if flagTag.Shorthand != "" && flagTag.Shorthand != "-" {
-					flag.Int32SliceVarP((*[]int32)(fv.Addr().UnsafePointer()), flagTag.Name, flagTag.Shorthand, convertValue(flagTag.Name, flagTag.Value, "int32", true).([]int32), flagTag.Usage)
-				} else {
-					flag.Int32SliceVar((*[]int32)(fv.Addr().UnsafePointer()), flagTag.Name, convertValue(flagTag.Name, flagTag.Value, "int32", true).([]int32), flagTag.Usage)
-				}
-			case reflect.Int64:
-				if flagTag.Shorthand != "" && flagTag.Shorthand != "-" {
-					flag.DurationSliceVarP((*[]time.Duration)(fv.Addr().UnsafePointer()), flagTag.Name, flagTag.Shorthand, convertValue(flagTag.Name, flagTag.Value, "duration", true).([]time.Duration), flagTag.Usage)
-				} else {
-					flag.DurationSliceVar((*[]time.Duration)(fv.Addr().UnsafePointer()), flagTag.Name, convertValue(flagTag.Name, flagTag.Value, "duration", true).([]time.Duration), flagTag.Usage)
-				}
-			case reflect.Uint:
-				if flagTag.Shorthand != "" && flagTag.Shorthand != "-" {
-					flag.UintSliceVarP((*[]uint)(fv.Addr().UnsafePointer()), flagTag.Name, flagTag.Shorthand, convertValue(flagTag.Name, flagTag.Value, "uint", true).([]uint), flagTag.Usage)
-				} else {
-					flag.UintSliceVar((*[]uint)(fv.Addr().UnsafePointer()), flagTag.Name, convertValue(flagTag.Name, flagTag.Value, "uint", true).([]uint), flagTag.Usage)
-				}
-			case reflect.Float32:
-				if flagTag.Shorthand != "" && flagTag.Shorthand != "-" {
-					flag.Float32SliceVarP((*[]float32)(fv.Addr().UnsafePointer()), flagTag.Name, flagTag.Shorthand, convertValue(flagTag.Name, flagTag.Value, "float32", true).([]float32), flagTag.Usage)
-				} else {
-					flag.Float32SliceVar((*[]float32)(fv.Addr().UnsafePointer()), flagTag.Name, convertValue(flagTag.Name, flagTag.Value, "float32", true).([]float32), flagTag.Usage)
-				}
-			case reflect.Float64:
-				if flagTag.Shorthand != "" && flagTag.Shorthand != "-" {
-					flag.Float64SliceVarP((*[]float64)(fv.Addr().UnsafePointer()), flagTag.Name, flagTag.Shorthand, convertValue(flagTag.Name, flagTag.Value, "float64", true).([]float64), flagTag.Usage)
-				} else {
-					flag.Float64SliceVar((*[]float64)(fv.Addr().UnsafePointer()), flagTag.Name, convertValue(flagTag.Name, flagTag.Value, "float64", true).([]float64), flagTag.Usage)
-				}
-			case reflect.Bool:
-				if flagTag.Shorthand != "" && flagTag.Shorthand != "-" {
-					flag.BoolSliceVarP((*[]bool)(fv.Addr().UnsafePointer()), flagTag.Name, flagTag.Shorthand, convertValue(flagTag.Name, flagTag.Value, "bool", true).([]bool), flagTag.Usage)
-				} else {
-					flag.BoolSliceVar((*[]bool)(fv.Addr().UnsafePointer()), flagTag.Name, convertValue(flagTag.Name, flagTag.Value, "bool", true).([]bool), flagTag.Usage)
-				}
-			default:
-				panic(fmt.Sprintf("unsupported type: %T", fv.Interface()))
-			}
+		return true
+	}
+	t := f.Field.Type()
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if !isPlainType(t) {
+		return false
+	}
+	switch f.Field.Kind() {
+	case reflect.Slice:
+		switch f.Field.Type().Elem().Kind() {
 		case reflect.String:
-			if flagTag.Shorthand != "" && flagTag.Shorthand != "-" {
-				flag.StringVarP((*string)(fv.Addr().UnsafePointer()), flagTag.Name, flagTag.Shorthand, flagTag.Value, flagTag.Usage)
-			} else {
-				flag.StringVar((*string)(fv.Addr().UnsafePointer()), flagTag.Name, flagTag.Value, flagTag.Usage)
-			}
+			p := (*[]string)(f.Field.Addr().UnsafePointer())
+			fs.StringSliceVarP(p, f.Name, f.Shorthand, *p, f.Usage)
 		case reflect.Int:
-			if flagTag.Shorthand != "" && flagTag.Shorthand != "-" {
-				flag.IntVarP((*int)(fv.Addr().UnsafePointer()), flagTag.Name, flagTag.Shorthand, convertValue(flagTag.Name, flagTag.Value, "int").(int), flagTag.Usage)
-			} else {
-				flag.IntVar((*int)(fv.Addr().UnsafePointer()), flagTag.Name, convertValue(flagTag.Name, flagTag.Value, "int").(int), flagTag.Usage)
-			}
-		case reflect.Int8:
-			if flagTag.Shorthand != "" && flagTag.Shorthand != "-" {
-				flag.Int8VarP((*int8)(fv.Addr().UnsafePointer()), flagTag.Name, flagTag.Shorthand, convertValue(flagTag.Name, flagTag.Value, "int8").(int8), flagTag.Usage)
-			} else {
-				flag.Int8Var((*int8)(fv.Addr().UnsafePointer()), flagTag.Name, convertValue(flagTag.Name, flagTag.Value, "int8").(int8), flagTag.Usage)
-			}
-		case reflect.Int16:
-			if flagTag.Shorthand != "" && flagTag.Shorthand != "-" {
-				flag.Int16VarP((*int16)(fv.Addr().UnsafePointer()), flagTag.Name, flagTag.Shorthand, convertValue(flagTag.Name, flagTag.Value, "int16").(int16), flagTag.Usage)
-			} else {
-				flag.Int16Var((*int16)(fv.Addr().UnsafePointer()), flagTag.Name, convertValue(flagTag.Name, flagTag.Value, "int16").(int16), flagTag.Usage)
-			}
+			p := (*[]int)(f.Field.Addr().UnsafePointer())
+			fs.IntSliceVarP(p, f.Name, f.Shorthand, *p, f.Usage)
 		case reflect.Int32:
-			if flagTag.Shorthand != "" && flagTag.Shorthand != "-" {
-				flag.Int32VarP((*int32)(fv.Addr().UnsafePointer()), flagTag.Name, flagTag.Shorthand, convertValue(flagTag.Name, flagTag.Value, "int32").(int32), flagTag.Usage)
-			} else {
-				flag.Int32Var((*int32)(fv.Addr().UnsafePointer()), flagTag.Name, convertValue(flagTag.Name, flagTag.Value, "int32").(int32), flagTag.Usage)
-			}
+			p := (*[]int32)(f.Field.Addr().UnsafePointer())
+			fs.Int32SliceVarP(p, f.Name, f.Shorthand, *p, f.Usage)
 		case reflect.Int64:
-			if flagTag.Shorthand != "" && flagTag.Shorthand != "-" {
-				flag.Int64VarP((*int64)(fv.Addr().UnsafePointer()), flagTag.Name, flagTag.Shorthand, convertValue(flagTag.Name, flagTag.Value, "int64").(int64), flagTag.Usage)
+			if f.Field.Type().Elem() == durationType {
+				p := (*[]time.Duration)(f.Field.Addr().UnsafePointer())
+				fs.DurationSliceVarP(p, f.Name, f.Shorthand, *p, f.Usage)
 			} else {
-				flag.Int64Var((*int64)(fv.Addr().UnsafePointer()), flagTag.Name, convertValue(flagTag.Name, flagTag.Value, "int64").(int64), flagTag.Usage)
+				p := (*[]int64)(f.Field.Addr().UnsafePointer())
+				fs.Int64SliceVarP(p, f.Name, f.Shorthand, *p, f.Usage)
 			}
 		case reflect.Uint:
-			if flagTag.Shorthand != "" && flagTag.Shorthand != "-" {
-				flag.UintVarP((*uint)(fv.Addr().UnsafePointer()), flagTag.Name, flagTag.Shorthand, convertValue(flagTag.Name, flagTag.Value, "uint").(uint), flagTag.Usage)
-			} else {
-				flag.UintVar((*uint)(fv.Addr().UnsafePointer()), flagTag.Name, convertValue(flagTag.Name, flagTag.Value, "uint").(uint), flagTag.Usage)
-			}
-		case reflect.Uint8:
-			if flagTag.Shorthand != "" && flagTag.Shorthand != "-" {
-				flag.Uint8VarP((*uint8)(fv.Addr().UnsafePointer()), flagTag.Name, flagTag.Shorthand, convertValue(flagTag.Name, flagTag.Value, "uint8").(uint8), flagTag.Usage)
-			} else {
-				flag.Uint8Var((*uint8)(fv.Addr().UnsafePointer()), flagTag.Name, convertValue(flagTag.Name, flagTag.Value, "uint8").(uint8), flagTag.Usage)
-			}
-		case reflect.Uint16:
-			if flagTag.Shorthand != "" && flagTag.Shorthand != "-" {
-				flag.Uint16VarP((*uint16)(fv.Addr().UnsafePointer()), flagTag.Name, flagTag.Shorthand, convertValue(flagTag.Name, flagTag.Value, "uint16").(uint16), flagTag.Usage)
-			} else {
-				flag.Uint16Var((*uint16)(fv.Addr().UnsafePointer()), flagTag.Name, convertValue(flagTag.Name, flagTag.Value, "uint16").(uint16), flagTag.Usage)
-			}
-		case reflect.Uint32:
-			if flagTag.Shorthand != "" && flagTag.Shorthand != "-" {
-				flag.Uint32VarP((*uint32)(fv.Addr().UnsafePointer()), flagTag.Name, flagTag.Shorthand, convertValue(flagTag.Name, flagTag.Value, "uint32").(uint32), flagTag.Usage)
-			} else {
-				flag.Uint32Var((*uint32)(fv.Addr().UnsafePointer()), flagTag.Name, convertValue(flagTag.Name, flagTag.Value, "uint32").(uint32), flagTag.Usage)
-			}
-		case reflect.Uint64:
-			if flagTag.Shorthand != "" && flagTag.Shorthand != "-" {
-				flag.Uint64VarP((*uint64)(fv.Addr().UnsafePointer()), flagTag.Name, flagTag.Shorthand, convertValue(flagTag.Name, flagTag.Value, "uint64").(uint64), flagTag.Usage)
-			} else {
-				flag.Uint64Var((*uint64)(fv.Addr().UnsafePointer()), flagTag.Name, convertValue(flagTag.Name, flagTag.Value, "uint64").(uint64), flagTag.Usage)
-			}
+			p := (*[]uint)(f.Field.Addr().UnsafePointer())
+			fs.UintSliceVarP(p, f.Name, f.Shorthand, *p, f.Usage)
 		case reflect.Float32:
-			if flagTag.Shorthand != "" && flagTag.Shorthand != "-" {
-				flag.Float32VarP((*float32)(fv.Addr().UnsafePointer()), flagTag.Name, flagTag.Shorthand, convertValue(flagTag.Name, flagTag.Value, "float32").(float32), flagTag.Usage)
-			} else {
-				flag.Float32Var((*float32)(fv.Addr().UnsafePointer()), flagTag.Name, convertValue(flagTag.Name, flagTag.Value, "float32").(float32), flagTag.Usage)
-			}
+			p := (*[]float32)(f.Field.Addr().UnsafePointer())
+			fs.Float32SliceVarP(p, f.Name, f.Shorthand, *p, f.Usage)
 		case reflect.Float64:
-			if flagTag.Shorthand != "" && flagTag.Shorthand != "-" {
-				flag.Float64VarP((*float64)(fv.Addr().UnsafePointer()), flagTag.Name, flagTag.Shorthand, convertValue(flagTag.Name, flagTag.Value, "float64").(float64), flagTag.Usage)
-			} else {
-				flag.Float64Var((*float64)(fv.Addr().UnsafePointer()), flagTag.Name, convertValue(flagTag.Name, flagTag.Value, "float64").(float64), flagTag.Usage)
-			}
+			p := (*[]float64)(f.Field.Addr().UnsafePointer())
+			fs.Float64SliceVarP(p, f.Name, f.Shorthand, *p, f.Usage)
 		case reflect.Bool:
-			if flagTag.Shorthand != "" && flagTag.Shorthand != "-" {
-				flag.BoolVarP((*bool)(fv.Addr().UnsafePointer()), flagTag.Name, flagTag.Shorthand, convertValue(flagTag.Name, flagTag.Value, "bool").(bool), flagTag.Usage)
-			} else {
-				flag.BoolVar((*bool)(fv.Addr().UnsafePointer()), flagTag.Name, convertValue(flagTag.Name, flagTag.Value, "bool").(bool), flagTag.Usage)
-			}
+			p := (*[]bool)(f.Field.Addr().UnsafePointer())
+			fs.BoolSliceVarP(p, f.Name, f.Shorthand, *p, f.Usage)
 		default:
-			panic(fmt.Sprintf("unsupported type: %T", fv.Interface()))
+			return false
 		}
+	case reflect.String:
+		p := (*string)(f.Field.Addr().UnsafePointer())
+		fs.StringVarP(p, f.Name, f.Shorthand, *p, f.Usage)
+	case reflect.Int:
+		p := (*int)(f.Field.Addr().UnsafePointer())
+		fs.IntVarP(p, f.Name, f.Shorthand, *p, f.Usage)
+	case reflect.Int8:
+		p := (*int8)(f.Field.Addr().UnsafePointer())
+		fs.Int8VarP(p, f.Name, f.Shorthand, *p, f.Usage)
+	case reflect.Int16:
+		p := (*int16)(f.Field.Addr().UnsafePointer())
+		fs.Int16VarP(p, f.Name, f.Shorthand, *p, f.Usage)
+	case reflect.Int32:
+		p := (*int32)(f.Field.Addr().UnsafePointer())
+		fs.Int32VarP(p, f.Name, f.Shorthand, *p, f.Usage)
+	case reflect.Int64:
+		if f.Field.Type() == durationType {
+			p := (*time.Duration)(f.Field.Addr().UnsafePointer())
+			fs.DurationVarP(p, f.Name, f.Shorthand, *p, f.Usage)
+		} else {
+			p := (*int64)(f.Field.Addr().UnsafePointer())
+			fs.Int64VarP(p, f.Name, f.Shorthand, *p, f.Usage)
+		}
+	case reflect.Uint:
+		p := (*uint)(f.Field.Addr().UnsafePointer())
+		fs.UintVarP(p, f.Name, f.Shorthand, *p, f.Usage)
+	case reflect.Uint8:
+		p := (*uint8)(f.Field.Addr().UnsafePointer())
+		fs.Uint8VarP(p, f.Name, f.Shorthand, *p, f.Usage)
+	case reflect.Uint16:
+		p := (*uint16)(f.Field.Addr().UnsafePointer())
+		fs.Uint16VarP(p, f.Name, f.Shorthand, *p, f.Usage)
+	case reflect.Uint32:
+		p := (*uint32)(f.Field.Addr().UnsafePointer())
+		fs.Uint32VarP(p, f.Name, f.Shorthand, *p, f.Usage)
+	case reflect.Uint64:
+		p := (*uint64)(f.Field.Addr().UnsafePointer())
+		fs.Uint64VarP(p, f.Name, f.Shorthand, *p, f.Usage)
+	case reflect.Float32:
+		p := (*float32)(f.Field.Addr().UnsafePointer())
+		fs.Float32VarP(p, f.Name, f.Shorthand, *p, f.Usage)
+	case reflect.Float64:
+		p := (*float64)(f.Field.Addr().UnsafePointer())
+		fs.Float64VarP(p, f.Name, f.Shorthand, *p, f.Usage)
+	case reflect.Bool:
+		p := (*bool)(f.Field.Addr().UnsafePointer())
+		fs.BoolVarP(p, f.Name, f.Shorthand, *p, f.Usage)
+	default:
+		return false
 	}
-	return nil
-}
-
-func MustBindPFlags(flag *pflag.FlagSet, a any) {
-	err := BindPFlags(flag, a)
-	if err != nil {
-		panic(err)
-	}
+	return true
 }
@@ -1,8 +1,15 @@
 package bindflags
 
 import (
+	"bytes"
+	"errors"
 	"github.com/spf13/pflag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestBindPFlags(t *testing.T) {
@@ -35,3 +42,209 @@ func (s studentDesc) GetPFlagTag() IpFlagTag {
 		Usage:     "student description",
 	}
 }
+
+type envConfig struct {
+	Host  string   `flag:"name:host;value:localhost;env:TEST_BIND_HOST"`
+	Port  int      `flag:"name:port;shorthand:p;value:80;env:TEST_BIND_PORT"`
+	Tags  []string `flag:"name:tags;env:TEST_BIND_TAGS"`
+	Debug bool     `flag:"debug;d;false;debug mode;TEST_BIND_DEBUG"`
+}
+
+func TestBindPFlagsEnv(t *testing.T) {
+	t.Setenv("TEST_BIND_HOST", "example.com")
+	t.Setenv("TEST_BIND_PORT", "8080")
+	t.Setenv("TEST_BIND_TAGS", "a,b")
+	t.Setenv("TEST_BIND_DEBUG", "true")
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	c := new(envConfig)
+	MustBindPFlags(f, c)
+	if err := f.Parse([]string{"-p", "9090", "--tags", "c"}); err != nil {
+		t.Fatal(err)
+	}
+	if c.Host != "example.com" || c.Port != 9090 || !c.Debug {
+		t.Fatalf("unexpected values: %#v", c)
+	}
+	if len(c.Tags) != 1 || c.Tags[0] != "c" {
+		t.Fatalf("command line slice should replace env value, got %v", c.Tags)
+	}
+	if u := f.Lookup("host").Usage; u != "[$TEST_BIND_HOST]" {
+		t.Fatalf("unexpected usage %q", u)
+	}
+}
+
+func TestBindPFlagsEnvMap(t *testing.T) {
+	t.Setenv("TEST_BIND_LABELS", "a=1")
+	t.Setenv("TEST_BIND_GATES", "A=true")
+	t.Setenv("TEST_BIND_LIMITS", "x=1")
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	c := &struct {
+		Labels map[string]string `flag:"name:labels;env:TEST_BIND_LABELS"`
+		Gates  map[string]bool   `flag:"name:gates;env:TEST_BIND_GATES"`
+		Limits map[string]int    `flag:"name:limits;env:TEST_BIND_LIMITS"`
+	}{}
+	MustBindPFlags(f, c)
+	if c.Labels["a"] != "1" || !c.Gates["A"] || c.Limits["x"] != 1 {
+		t.Fatalf("unexpected env values: %#v", c)
+	}
+	if err := f.Parse([]string{"--labels", "b=2", "--gates", "B=false", "--gates", "C=true"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Labels) != 1 || c.Labels["b"] != "2" {
+		t.Fatalf("command line map should replace env value, got %v", c.Labels)
+	}
+	if len(c.Gates) != 2 || c.Gates["B"] || !c.Gates["C"] {
+		t.Fatalf("command line map should replace env value, got %v", c.Gates)
+	}
+	if len(c.Limits) != 1 || c.Limits["x"] != 1 {
+		t.Fatalf("unexpected limits %v", c.Limits)
+	}
+}
+
+func TestBindPFlagsErrors(t *testing.T) {
+	var tagErr *TagError
+	err := BindPFlags(pflag.NewFlagSet("test", pflag.ContinueOnError), &struct {
+		Port int `flag:"name:port;value:http"`
+	}{})
+	if !errors.As(err, &tagErr) || tagErr.Field != "Port" || tagErr.Tag != "name:port;value:http" {
+		t.Fatalf("expected TagError, got %v", err)
+	}
+	err = BindPFlags(pflag.NewFlagSet("test", pflag.ContinueOnError), &struct {
+		Port int `flag:"name:port;bad:1"`
+	}{})
+	if !errors.As(err, &tagErr) {
+		t.Fatalf("expected TagError, got %v", err)
+	}
+	var typeErr *UnsupportedTypeError
+	err = BindPFlags(pflag.NewFlagSet("test", pflag.ContinueOnError), &struct {
+		C chan int `flag:"name:c"`
+	}{})
+	if !errors.As(err, &typeErr) || typeErr.Field != "C" || typeErr.Type != reflect.TypeOf(make(chan int)) {
+		t.Fatalf("expected UnsupportedTypeError, got %v", err)
+	}
+}
+
+func TestBindPFlagsSliceDefault(t *testing.T) {
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	c := &struct {
+		Ports []int           `flag:"name:ports;value:[80,443]"`
+		Waits []time.Duration `flag:"name:waits;value:[\"1s\",2000000000]"`
+	}{}
+	MustBindPFlags(f, c)
+	if len(c.Ports) != 2 || c.Ports[1] != 443 || len(c.Waits) != 2 || c.Waits[1] != 2*time.Second {
+		t.Fatalf("unexpected defaults: %v %v", c.Ports, c.Waits)
+	}
+}
+
+type sliceConfig struct {
+	Buckets []int64         `flag:"name:buckets;value:[1,10,100]"`
+	Waits   []time.Duration `flag:"name:waits"`
+	Int8s   []int8          `flag:"name:int8s;value:[-1]"`
+	Int16s  []int16         `flag:"name:int16s"`
+	Uint16s []uint16        `flag:"name:uint16s;value:[8080]"`
+	Uint32s []uint32        `flag:"name:uint32s"`
+	Uint64s []uint64        `flag:"name:uint64s"`
+	Names   [2]string       `flag:"name:names;value:[\"a\"]"`
+	Weights [3]float64      `flag:"name:weights"`
+}
+
+func TestBindPFlagsSliceTypes(t *testing.T) {
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	c := new(sliceConfig)
+	MustBindPFlags(f, c)
+	if len(c.Buckets) != 3 || c.Buckets[2] != 100 || len(c.Int8s) != 1 || c.Int8s[0] != -1 || c.Uint16s[0] != 8080 || c.Names[0] != "a" {
+		t.Fatalf("unexpected defaults: %#v", c)
+	}
+	if typ := f.Lookup("buckets").Value.Type(); typ != "int64Slice" {
+		t.Fatalf("unexpected type %q", typ)
+	}
+	if typ := f.Lookup("uint16s").Value.Type(); typ != "uint16Slice" {
+		t.Fatalf("unexpected type %q", typ)
+	}
+	err := f.Parse([]string{"--buckets", "5,50", "--waits", "1s", "--int16s", "-300", "--int16s", "300",
+		"--uint16s", "1,2", "--uint32s", "4000000000", "--uint64s", "18446744073709551615", "--names", "x,y", "--weights", "0.5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Buckets) != 2 || c.Buckets[1] != 50 || len(c.Waits) != 1 || c.Waits[0] != time.Second {
+		t.Fatalf("unexpected values: %#v", c)
+	}
+	if len(c.Int16s) != 2 || c.Int16s[0] != -300 || len(c.Uint16s) != 2 || c.Uint32s[0] != 4000000000 || c.Uint64s[0] != 1<<64-1 {
+		t.Fatalf("unexpected values: %#v", c)
+	}
+	if c.Names != [2]string{"x", "y"} || c.Weights != [3]float64{0.5} {
+		t.Fatalf("unexpected arrays: %v %v", c.Names, c.Weights)
+	}
+	if s := f.Lookup("int16s").Value.String(); s != "[-300,300]" {
+		t.Fatalf("unexpected string %q", s)
+	}
+	if err = f.Set("names", "z"); err == nil {
+		t.Fatal("expected an error for a full array")
+	}
+	if err = f.Set("int8s", "300"); err == nil {
+		t.Fatal("expected an out of range error")
+	}
+}
+
+func TestBindPFlagsTime(t *testing.T) {
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	c := &struct {
+		Timeout time.Duration  `flag:"name:timeout;shorthand:t;value:30s;max:1m"`
+		Retries int64          `flag:"name:retries;value:3"`
+		Since   time.Time      `flag:"name:since;value:2024-01-02T03:04:05Z"`
+		Until   time.Time      `flag:"name:until;layout:2006-01-02"`
+		Poll    *time.Duration `flag:"name:poll"`
+	}{}
+	b := new(Binder)
+	if err := b.BindPFlags(f, c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Timeout != 30*time.Second || c.Retries != 3 || c.Since.Year() != 2024 || !c.Until.IsZero() {
+		t.Fatalf("unexpected defaults: %#v", c)
+	}
+	if typ := f.Lookup("timeout").Value.Type(); typ != "duration" {
+		t.Fatalf("unexpected type %q", typ)
+	}
+	if def := f.Lookup("until").DefValue; def != "" {
+		t.Fatalf("unexpected default %q", def)
+	}
+	if err := f.Parse([]string{"-t", "2m", "--until", "2025-06-30", "--poll", "500ms"}); err != nil {
+		t.Fatal(err)
+	}
+	if c.Timeout != 2*time.Minute || c.Until != time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC) || *c.Poll != 500*time.Millisecond {
+		t.Fatalf("unexpected values: %#v", c)
+	}
+	if s := f.Lookup("until").Value.String(); s != "2025-06-30" {
+		t.Fatalf("unexpected string %q", s)
+	}
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("since: 2023-05-06T07:08:09Z\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.LoadConfigFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if c.Since != time.Date(2023, 5, 6, 7, 8, 9, 0, time.UTC) {
+		t.Fatalf("unexpected since %v", c.Since)
+	}
+	if err := Validate(c); err == nil {
+		t.Fatal("expected a max error")
+	}
+	if err := f.Set("until", "30/06/2025"); err == nil {
+		t.Fatal("expected an invalid value error")
+	}
+}
+
+func TestBindPFlagsZeroArray(t *testing.T) {
+	var buf bytes.Buffer
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	f.SetOutput(&buf)
+	c := &struct {
+		Names [3]string `flag:"name:names"`
+		Pair  [2]int    `flag:"name:pair;value:[1,2]"`
+	}{}
+	MustBindPFlags(f, c)
+	f.PrintDefaults()
+	if out := buf.String(); strings.Count(out, "(default") != 1 || !strings.Contains(out, "(default [1,2])") {
+		t.Fatalf("only the default of pair should be shown:\n%s", out)
+	}
+}
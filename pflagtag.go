@@ -5,6 +5,13 @@ type PFlagTag struct {
 	Shorthand string
 	Value     string
 	Usage     string
+	Env       string
+	// Persistent registers the flag, or every flag of a nested struct, on the persistent flags of a cobra command
+	Persistent bool
+	// Encoding is the encoding of a []byte field: hex, the default, or base64
+	Encoding string
+	// Layout is the time.Parse layout of a time.Time field, time.RFC3339 by default
+	Layout string
 }
 
 func (f *PFlagTag) GetName() string {
@@ -19,3 +26,12 @@ func (f *PFlagTag) GetValue() string {
 func (f *PFlagTag) GetUsage() string {
 	return f.Usage
 }
+func (f *PFlagTag) GetEnv() string {
+	return f.Env
+}
+func (f *PFlagTag) GetEncoding() string {
+	return f.Encoding
+}
+func (f *PFlagTag) GetLayout() string {
+	return f.Layout
+}
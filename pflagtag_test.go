@@ -31,3 +31,29 @@ func TestPFlagTag(t *testing.T) {
 		}
 	}
 }
+
+func TestScanTagBareNames(t *testing.T) {
+	testCases := []struct {
+		tag   string
+		names []string
+		want  map[string]string
+	}{
+		{"force;f;false;required", pFlagNames, map[string]string{"name": "force", "shorthand": "f", "value": "false", "required": "true"}},
+		{"port;p;8080;port number;required", pFlagNames, map[string]string{"name": "port", "shorthand": "p", "value": "8080", "usage": "port number", "required": "true"}},
+		{"force;f;false;usage:required", pFlagNames, map[string]string{"name": "force", "shorthand": "f", "value": "false", "usage": "required"}},
+		{"force;f;false;usage;ENV;required", pFlagNames, map[string]string{"name": "force", "shorthand": "f", "value": "false", "usage": "usage", "env": "ENV", "required": "true"}},
+		{"name:verbose;shorthand:v;persistent", pFlagNames, map[string]string{"name": "verbose", "shorthand": "v", "persistent": "true"}},
+		{"port;required;usage:the port", flagNames, map[string]string{"name": "port", "required": "true", "usage": "the port"}},
+		{"0;required", argNames, map[string]string{"index": "0", "required": "true"}},
+		{"0;src;required", argNames, map[string]string{"index": "0", "name": "src", "required": "true"}},
+	}
+	for _, tc := range testCases {
+		kv, err := scanTag(tc.tag, tc.names)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(kv) != fmt.Sprint(tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.tag, kv, tc.want)
+		}
+	}
+}
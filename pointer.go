@@ -0,0 +1,108 @@
+package bindflags
+
+import (
+	"reflect"
+
+	"github.com/spf13/pflag"
+)
+
+// newPtrValue returns the value of the nil pointer field ptr bound with Binder.NilPointers,
+// a *ptrSliceValue when the element is a slice so that env and config values are replaced by the command line
+func newPtrValue(ptr reflect.Value, flagTag *PFlagTag) (pflag.Value, error) {
+	value, _, err := newValue(reflect.New(ptr.Type().Elem()).Elem(), flagTag)
+	if err != nil {
+		return nil, err
+	}
+	v := &ptrValue{ptr: ptr, tag: flagTag, typ: value.Type()}
+	if _, ok := value.(boolFlag); ok {
+		// false is shown as no default by PrintDefaults
+		v.zero = value.String()
+		return &boolValue{v}, nil
+	}
+	if _, ok := value.(pflag.SliceValue); ok {
+		return &ptrSliceValue{v}, nil
+	}
+	return v, nil
+}
+
+// ptrValue is the value of a nil pointer field, which stays nil until the first Set allocates its element
+type ptrValue struct {
+	ptr reflect.Value
+	tag *PFlagTag
+	// value sets the allocated element, nil while the field is nil
+	value pflag.Value
+	typ   string
+	// zero is the String of the nil field
+	zero string
+}
+
+// set calls set with the value of the element, allocated on the first call; the field stays nil when set fails
+func (v *ptrValue) set(set func(value pflag.Value) error) error {
+	if v.value != nil {
+		return set(v.value)
+	}
+	elem := reflect.New(v.ptr.Type().Elem())
+	value, _, err := newValue(elem.Elem(), v.tag)
+	if err != nil {
+		return err
+	}
+	if err = set(value); err != nil {
+		return err
+	}
+	v.ptr.Set(elem)
+	v.value = value
+	return nil
+}
+
+func (v *ptrValue) Set(s string) error {
+	return v.set(func(value pflag.Value) error {
+		return value.Set(s)
+	})
+}
+
+func (v *ptrValue) replace(s string) error {
+	return v.set(func(value pflag.Value) error {
+		if r, ok := value.(replacer); ok {
+			return r.replace(s)
+		}
+		return value.Set(s)
+	})
+}
+
+func (v *ptrValue) String() string {
+	if v == nil {
+		return ""
+	}
+	if v.value == nil {
+		return v.zero
+	}
+	return v.value.String()
+}
+
+func (v *ptrValue) Type() string {
+	return v.typ
+}
+
+// ptrSliceValue is the ptrValue of a pointer to a slice or an array
+type ptrSliceValue struct {
+	*ptrValue
+}
+
+func (v *ptrSliceValue) Append(s string) error {
+	return v.set(func(value pflag.Value) error {
+		return value.(pflag.SliceValue).Append(s)
+	})
+}
+
+func (v *ptrSliceValue) Replace(items []string) error {
+	return v.set(func(value pflag.Value) error {
+		return value.(pflag.SliceValue).Replace(items)
+	})
+}
+
+func (v *ptrSliceValue) GetSlice() []string {
+	if v.value == nil {
+		return nil
+	}
+	return v.value.(pflag.SliceValue).GetSlice()
+}
@@ -0,0 +1,135 @@
+package bindflags
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+type nilConfig struct {
+	Debug   *bool          `flag:"name:debug;shorthand:d"`
+	Workers *int           `flag:"name:workers;min:1;env:TEST_NIL_WORKERS"`
+	Tags    *[]string      `flag:"name:tags;env:TEST_NIL_TAGS"`
+	Timeout *time.Duration `flag:"name:timeout;value:30s"`
+	Name    *string        `flag:"name:name;required"`
+	Ports   *[]int         `flag:"name:ports"`
+	URL     *url.URL       `flag:"name:url"`
+	DB      *struct {
+		Port *int `flag:"name:port"`
+	} `flag:"db"`
+}
+
+func TestBinderNilPointers(t *testing.T) {
+	pf := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	if err := (&Binder{NilPointers: true}).BindPFlags(pf, new(nilConfig)); err != nil {
+		t.Fatal(err)
+	}
+	for name, def := range map[string]string{"debug": "false", "workers": "", "ports": "", "url": "", "timeout": "30s"} {
+		if d := pf.Lookup(name).DefValue; d != def {
+			t.Errorf("unexpected default of %s: %q", name, d)
+		}
+	}
+	var buf bytes.Buffer
+	pf.SetOutput(&buf)
+	pf.PrintDefaults()
+	if out := buf.String(); strings.Contains(out, "(default )") || strings.Count(out, "(default") != 1 {
+		t.Fatalf("only the default of timeout should be shown:\n%s", out)
+	}
+	t.Setenv("TEST_NIL_TAGS", "a,b")
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	c := new(nilConfig)
+	b := &Binder{NilPointers: true}
+	if err := b.BindPFlags(f, c); err != nil {
+		t.Fatal(err)
+	}
+	// the value of the environment is the default shown by the help
+	buf.Reset()
+	f.SetOutput(&buf)
+	f.PrintDefaults()
+	if out := buf.String(); strings.Contains(out, "(default )") || !strings.Contains(out, "(default [a,b])") {
+		t.Fatalf("the default of tags should be its env value:\n%s", out)
+	}
+	if c.Debug != nil || c.Workers != nil || c.Name != nil || c.DB == nil || c.DB.Port != nil {
+		t.Fatalf("pointers should stay nil: %#v", c)
+	}
+	if c.Timeout == nil || *c.Timeout != 30*time.Second || c.Tags == nil || len(*c.Tags) != 2 {
+		t.Fatalf("unexpected defaults: %#v", c)
+	}
+	if typ := f.Lookup("workers").Value.Type(); typ != "int" {
+		t.Fatalf("unexpected type %q", typ)
+	}
+	var verr ValidationErrors
+	if err := Validate(c); !errors.As(err, &verr) || len(verr) != 1 || verr[0].Flag != "name" {
+		t.Fatalf("expected a required error only, got %v", err)
+	}
+	if err := f.Parse([]string{"-d", "--tags", "c", "--name", "x", "--workers", "0"}); err != nil {
+		t.Fatal(err)
+	}
+	if c.Debug == nil || !*c.Debug || len(*c.Tags) != 1 || (*c.Tags)[0] != "c" || *c.Name != "x" || *c.Workers != 0 {
+		t.Fatalf("unexpected values: %#v", c)
+	}
+	if err := Validate(c); err == nil {
+		t.Fatal("expected a min error")
+	}
+	if err := b.LoadConfig(strings.NewReader(`{"db":{"port":5432}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if c.DB.Port == nil || *c.DB.Port != 5432 {
+		t.Fatalf("the config file should allocate the pointer: %#v", c.DB)
+	}
+
+	t.Setenv("TEST_NIL_WORKERS", "many")
+	err := b.BindPFlags(pflag.NewFlagSet("test", pflag.ContinueOnError), new(nilConfig))
+	if err == nil {
+		t.Fatal("expected an invalid env error")
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	sc := &struct {
+		Debug   *bool `flag:"name:debug"`
+		Workers *int  `flag:"name:workers"`
+	}{}
+	if err = (&Binder{NilPointers: true}).BindFlags(fs, sc, "app"); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	fs.SetOutput(&buf)
+	fs.PrintDefaults()
+	if out := buf.String(); strings.Contains(out, "(default") {
+		t.Fatalf("no default should be shown:\n%s", out)
+	}
+	if err = fs.Parse([]string{"-app.debug"}); err != nil {
+		t.Fatal(err)
+	}
+	if sc.Debug == nil || !*sc.Debug || sc.Workers != nil {
+		t.Fatalf("unexpected values: %#v", sc)
+	}
+	if err = fs.Set("app.workers", "x"); err == nil || sc.Workers != nil {
+		t.Fatalf("an invalid value should leave the pointer nil, got %v", err)
+	}
+}
+
+func TestValidateNilPointerRequired(t *testing.T) {
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	c := &struct {
+		Compress *bool `flag:"name:compress;required"`
+	}{}
+	if err := (&Binder{NilPointers: true}).BindPFlags(f, c); err != nil {
+		t.Fatal(err)
+	}
+	if err := Validate(c); err == nil {
+		t.Fatal("expected a required error for a nil pointer")
+	}
+	if err := f.Parse([]string{"--compress=false"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Validate(c); err != nil {
+		t.Fatalf("a pointer to false should satisfy required: %v", err)
+	}
+}
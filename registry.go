@@ -0,0 +1,97 @@
+package bindflags
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/spf13/pflag"
+)
+
+// converter parses and formats the values of a type given to RegisterType
+type converter struct {
+	parse  func(s string) (reflect.Value, error)
+	format func(v reflect.Value) string
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[reflect.Type]*converter)
+)
+
+// RegisterType makes fields of type T bindable, including slices of T and maps with T keys or values.
+// parse converts a tag value, an environment variable, a config value or a command-line value to T,
+// format writes the value shown by PrintDefaults. A registered type takes precedence over the methods of T.
+// RegisterType 注册类型 T 的解析与格式化函数，使 T、[]T 以及键或值为 T 的 map 字段可以绑定
+func RegisterType[T any](parse func(s string) (T, error), format func(v T) string) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	conv := &converter{
+		parse: func(s string) (reflect.Value, error) {
+			v, err := parse(s)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			return reflect.ValueOf(&v).Elem(), nil
+		},
+		format: func(v reflect.Value) string {
+			return format(v.Interface().(T))
+		},
+	}
+	registryMu.Lock()
+	registry[t] = conv
+	registryMu.Unlock()
+}
+
+// lookupType returns the converter registered for t, or nil
+func lookupType(t reflect.Type) *converter {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return registry[t]
+}
+
+// registeredValue returns a pflag.Value for v when its type, or the element type of a slice or array, is registered; nil otherwise
+func registeredValue(v reflect.Value) pflag.Value {
+	if conv := lookupType(v.Type()); conv != nil {
+		return &typeValue{value: v, conv: conv}
+	}
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		if lookupType(v.Type().Elem()) != nil {
+			return newSliceValue(v)
+		}
+	}
+	return nil
+}
+
+// registeredName returns the name of the registered type t shown by PrintDefaults
+func registeredName(t reflect.Type) string {
+	if t.Name() == "" {
+		return t.String()
+	}
+	return t.Name()
+}
+
+// typeValue is a pflag.Value for a field of a registered type
+type typeValue struct {
+	value reflect.Value
+	conv  *converter
+}
+
+func (v *typeValue) Set(s string) error {
+	x, err := v.conv.parse(s)
+	if err != nil {
+		return err
+	}
+	v.value.Set(x)
+	return nil
+}
+
+func (v *typeValue) String() string {
+	// the zero typeValue is created by flag.PrintDefaults to find the zero value
+	if !v.value.IsValid() {
+		return ""
+	}
+	return v.conv.format(v.value)
+}
+
+func (v *typeValue) Type() string {
+	return registeredName(v.value.Type())
+}
@@ -0,0 +1,128 @@
+package bindflags
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+// money is a third-party type without Set or UnmarshalText methods
+type money struct {
+	Cents    int64
+	Currency string
+}
+
+func parseMoney(s string) (money, error) {
+	var m money
+	var units, cents int64
+	if _, err := fmt.Sscanf(s, "%d.%02d %s", &units, &cents, &m.Currency); err != nil {
+		return m, fmt.Errorf("invalid money %q", s)
+	}
+	m.Cents = units*100 + cents
+	return m, nil
+}
+
+func formatMoney(m money) string {
+	return fmt.Sprintf("%d.%02d %s", m.Cents/100, m.Cents%100, m.Currency)
+}
+
+type region string
+
+func init() {
+	RegisterType(parseMoney, formatMoney)
+	RegisterType(func(s string) (region, error) {
+		if s != "eu" && s != "us" {
+			return "", fmt.Errorf("unknown region %q", s)
+		}
+		return region(strings.ToUpper(s)), nil
+	}, func(r region) string {
+		return strings.ToLower(string(r))
+	})
+}
+
+type registryConfig struct {
+	Price   money            `flag:"name:price;value:1.50 EUR"`
+	Region  region           `flag:"name:region;shorthand:r;value:eu"`
+	Regions []region         `flag:"name:regions;value:[\"eu\"]"`
+	Budget  map[region]money `flag:"name:budget;value:us=2.00 USD"`
+	Args    []money          `arg:"rest"`
+}
+
+func TestRegisterTypePFlags(t *testing.T) {
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	c := new(registryConfig)
+	MustBindPFlags(f, c)
+	if c.Price != (money{150, "EUR"}) || c.Region != "EU" || len(c.Regions) != 1 || c.Budget["US"] != (money{200, "USD"}) {
+		t.Fatalf("unexpected defaults: %#v", c)
+	}
+	if def := f.Lookup("price").DefValue; def != "1.50 EUR" {
+		t.Fatalf("unexpected default %q", def)
+	}
+	err := f.Parse([]string{"--price", "3.25 GBP", "-r", "us", "--regions", "us", "--regions", "eu", "--budget", "eu=1.00 EUR", "0.10 EUR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Price != (money{325, "GBP"}) || c.Region != "US" {
+		t.Fatalf("unexpected values: %#v", c)
+	}
+	if len(c.Regions) != 2 || c.Regions[0] != "US" || c.Regions[1] != "EU" {
+		t.Fatalf("unexpected regions %v", c.Regions)
+	}
+	if len(c.Budget) != 1 || c.Budget["EU"] != (money{100, "EUR"}) {
+		t.Fatalf("unexpected budget %v", c.Budget)
+	}
+	if s := f.Lookup("budget").Value.String(); s != "[eu=1.00 EUR]" {
+		t.Fatalf("unexpected string %q", s)
+	}
+	if err = BindArgs(f.Args(), c); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Args) != 1 || c.Args[0] != (money{10, "EUR"}) {
+		t.Fatalf("unexpected args %v", c.Args)
+	}
+	if err = f.Set("region", "asia"); err == nil {
+		t.Fatal("expected an invalid value error")
+	}
+}
+
+func TestRegisterTypeEnvConfig(t *testing.T) {
+	os.Setenv("TEST_REGIONS", "us,eu")
+	defer os.Unsetenv("TEST_REGIONS")
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	c := &struct {
+		Price   money    `flag:"name:price"`
+		Regions []region `flag:"name:regions;env:TEST_REGIONS"`
+	}{}
+	b := new(Binder)
+	if err := b.BindPFlags(f, c); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Parse([]string{"--regions", "eu"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.LoadConfig(strings.NewReader(`{"price":"9.99 USD"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if c.Price != (money{999, "USD"}) || len(c.Regions) != 1 || c.Regions[0] != "EU" {
+		t.Fatalf("unexpected values: %#v", c)
+	}
+}
+
+func TestRegisterTypeFlags(t *testing.T) {
+	f := flag.NewFlagSet("test", flag.ContinueOnError)
+	c := &struct {
+		Price   money    `flag:"name:price;value:1.00 EUR"`
+		Regions []region `flag:"name:regions"`
+	}{}
+	MustBindFlags(f, c)
+	if err := f.Parse([]string{"-price", "2.50 EUR", "-regions", "eu,us"}); err != nil {
+		t.Fatal(err)
+	}
+	if c.Price != (money{250, "EUR"}) || len(c.Regions) != 2 {
+		t.Fatalf("unexpected values: %#v", c)
+	}
+}
@@ -0,0 +1,88 @@
+package bindflags
+
+import "fmt"
+
+// SourceKind tells where the value of a bound flag came from
+type SourceKind int
+
+const (
+	// SourceDefault is the value of the tag or the zero value
+	SourceDefault SourceKind = iota
+	// SourceEnv is an environment variable
+	SourceEnv
+	// SourceConfig is a config file applied by LoadConfig, LoadConfigFile or Load
+	SourceConfig
+	// SourceFlag is the command line
+	SourceFlag
+)
+
+func (k SourceKind) String() string {
+	switch k {
+	case SourceDefault:
+		return "default"
+	case SourceEnv:
+		return "env"
+	case SourceConfig:
+		return "config"
+	case SourceFlag:
+		return "flag"
+	}
+	return fmt.Sprintf("SourceKind(%d)", int(k))
+}
+
+// Source describes where the value of a bound flag came from
+type Source struct {
+	Kind SourceKind
+	// Name is the environment variable for SourceEnv, or the dotted key in the config file for SourceConfig
+	Name string
+	// Path is the config file for SourceConfig, empty when it was read by LoadConfig
+	Path string
+}
+
+func (s Source) String() string {
+	switch s.Kind {
+	case SourceEnv:
+		return "env " + s.Name
+	case SourceConfig:
+		if s.Path == "" {
+			return "config " + s.Name
+		}
+		return "config " + s.Path + ":" + s.Name
+	}
+	return s.Kind.String()
+}
+
+// Sources returns where the value of every flag bound by the Binder came from, keyed by the dotted flag name.
+// Sources 返回 Binder 绑定的每个标志的值来源，键为以点分隔的标志名
+func (b *Binder) Sources() map[string]Source {
+	result := make(map[string]Source, len(b.bindings))
+	for _, bd := range b.bindings {
+		// flags of several FlagSets may share a name, like the flags of sibling commands: the one that was set wins
+		if src, ok := result[bd.name]; !ok || src.Kind == SourceDefault {
+			result[bd.name] = bd.origin()
+		}
+	}
+	return result
+}
+
+// Origin returns where the value of the flag name came from, false if the Binder did not bind it
+// Origin 返回标志 name 的值来源，未绑定时返回 false
+func (b *Binder) Origin(name string) (Source, bool) {
+	bindings := b.lookup(name)
+	if len(bindings) == 0 {
+		return Source{}, false
+	}
+	for _, bd := range bindings {
+		if src := bd.origin(); src.Kind != SourceDefault {
+			return src, true
+		}
+	}
+	return bindings[len(bindings)-1].origin(), true
+}
+
+func (bd *binding) origin() Source {
+	if bd.changed() {
+		return Source{Kind: SourceFlag}
+	}
+	return bd.source
+}
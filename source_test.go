@@ -0,0 +1,47 @@
+package bindflags
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestBinderSources(t *testing.T) {
+	t.Setenv("TEST_CONFIG_LEVEL", "warn")
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	if err := os.WriteFile(path, []byte("name: svc\nlevel: debug\ndb:\n  host: db.local\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	b := new(Binder)
+	if err := b.BindPFlags(f, new(fileConfig)); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Parse([]string{"--db.port", "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.LoadConfigFile(path); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]Source{
+		"name":    {Kind: SourceConfig, Name: "name", Path: path},
+		"level":   {Kind: SourceEnv, Name: "TEST_CONFIG_LEVEL"},
+		"ports":   {Kind: SourceDefault},
+		"db.host": {Kind: SourceConfig, Name: "db.host", Path: path},
+		"db.port": {Kind: SourceFlag},
+	}
+	sources := b.Sources()
+	for name, source := range want {
+		if sources[name] != source {
+			t.Errorf("%s: got %v, want %v", name, sources[name], source)
+		}
+	}
+	if s, ok := b.Origin("db.host"); !ok || s.String() != "config "+path+":db.host" {
+		t.Errorf("unexpected origin %v", s)
+	}
+	if _, ok := b.Origin("missing"); ok {
+		t.Error("expected no origin for an unbound flag")
+	}
+}
@@ -10,38 +10,78 @@ import (
 	"time"
 )
 
-var pFlagNames = []string{"name", "shorthand", "value", "usage"}
-var flagNames = []string{"name", "value", "usage"}
+var pFlagNames = []string{"name", "shorthand", "value", "usage", "env"}
+var flagNames = []string{"name", "value", "usage", "env"}
+
+// ruleNames are the validation rules checked by Validate, they can only be given as key-value pairs
+var ruleNames = []string{"required", "min", "max", "oneof", "pattern"}
+
+// optionNames are the options of a binding, they can only be given as key-value pairs.
+// shorthand is one too for the flag package, which ignores it, so that a tag with shorthand:n binds to both libraries.
+var optionNames = []string{"persistent", "encoding", "layout", "shorthand"}
+
+// isBareName reports whether the key name may be written alone, meaning name:true
+func isBareName(name string) bool {
+	return name == "required" || name == "persistent"
+}
 
 func scanFlagTag(s string) (*FlagTag, error) {
-	worlds, err := scanWorld(strings.NewReader(s), ';')
-	result, err := scanKV(worlds, flagNames)
+	result, err := scanTag(s, flagNames)
 	if err != nil {
 		return nil, err
 	}
-	result = formatKV(result)
+	if err = checkEncoding(result["encoding"]); err != nil {
+		return nil, err
+	}
 	return &FlagTag{
-		Name:  result["name"],
-		Value: result["value"],
-		Usage: result["usage"],
+		Name:     result["name"],
+		Value:    result["value"],
+		Usage:    result["usage"],
+		Env:      result["env"],
+		Encoding: result["encoding"],
+		Layout:   result["layout"],
 	}, nil
 }
 
 func scanPFlagTag(s string) (*PFlagTag, error) {
-	worlds, err := scanWorld(strings.NewReader(s), ';')
-	result, err := scanKV(worlds, pFlagNames)
+	result, err := scanTag(s, pFlagNames)
 	if err != nil {
 		return nil, err
 	}
-	result = formatKV(result)
+	var persistent bool
+	if v, ok := result["persistent"]; ok {
+		if persistent, err = strconv.ParseBool(v); err != nil {
+			return nil, fmt.Errorf("invalid persistent %q: %v", v, err)
+		}
+	}
+	if err = checkEncoding(result["encoding"]); err != nil {
+		return nil, err
+	}
 	return &PFlagTag{
-		Name:      result["name"],
-		Shorthand: result["shorthand"],
-		Value:     result["value"],
-		Usage:     result["usage"],
+		Name:       result["name"],
+		Shorthand:  result["shorthand"],
+		Value:      result["value"],
+		Usage:      result["usage"],
+		Env:        result["env"],
+		Persistent: persistent,
+		Encoding:   result["encoding"],
+		Layout:     result["layout"],
 	}, nil
 }
 
+// scanTag splits the tag s into its keys, flagNames gives the keys of the values without a key in order
+func scanTag(s string, flagNames []string) (map[string]string, error) {
+	worlds, err := scanWorld(strings.NewReader(s), ';')
+	if err != nil {
+		return nil, err
+	}
+	result, err := scanKV(worlds, flagNames)
+	if err != nil {
+		return nil, err
+	}
+	return formatKV(result), nil
+}
+
 func scanWorld(r io.RuneReader, split rune) ([]string, error) {
 	var builder strings.Builder
 	var item = make([]string, 0, 4)
@@ -90,6 +130,8 @@ func scanWorld(r io.RuneReader, split rune) ([]string, error) {
 	return item, nil
 }
 
+// scanKV reads the words of a tag. A word without a key takes the next one of flagNames not given with a key,
+// except a bare required or persistent, which is always the key, in every position and in every kind of tag.
 func scanKV(worlds []string, flagNames []string) (map[string]string, error) {
 	result := make(map[string]string)
 	defaultValues := []string{}
@@ -97,12 +139,16 @@ func scanKV(worlds []string, flagNames []string) (map[string]string, error) {
 	for _, word := range worlds {
 		n := strings.IndexByte(word, ':')
 		if n == -1 {
+			if name := strings.ToLower(strings.TrimSpace(word)); isBareName(name) {
+				result[name] = "true"
+				continue
+			}
 			defaultValues = append(defaultValues, word)
 			continue
 		}
 		tempName := strings.ToLower(strings.TrimSpace(word[:n]))
 		isScan = false
-		for _, fn := range flagNames {
+		for _, fn := range append(append(flagNames, ruleNames...), optionNames...) {
 			if fn == tempName {
 				result[tempName] = word[n+1:]
 				isScan = true
@@ -139,152 +185,128 @@ func formatKV(kv map[string]string) map[string]string {
 	return kv
 }
 
-func convertValue(name, value, typ string, isSlice ...bool) interface{} {
-	slice := false
-	if len(isSlice) > 0 {
-		slice = isSlice[0]
+// errUnsupportedType is returned by convertValue for a type it cannot convert
+var errUnsupportedType = errors.New("unsupported type")
+
+// convertValue converts the tag value to the default value of a flag of type typ, slices are written as JSON arrays.
+// An empty value is the zero value.
+func convertValue(value, typ string, isSlice ...bool) (interface{}, error) {
+	if len(isSlice) > 0 && isSlice[0] {
+		switch typ {
+		case "string":
+			return unmarshalSlice[string](value)
+		case "int":
+			return unmarshalSlice[int](value)
+		case "int8":
+			return unmarshalSlice[int8](value)
+		case "int16":
+			return unmarshalSlice[int16](value)
+		case "int32":
+			return unmarshalSlice[int32](value)
+		case "int64":
+			return unmarshalSlice[int64](value)
+		case "uint":
+			return unmarshalSlice[uint](value)
+		case "uint8":
+			return unmarshalSlice[uint8](value)
+		case "uint16":
+			return unmarshalSlice[uint16](value)
+		case "uint32":
+			return unmarshalSlice[uint32](value)
+		case "uint64":
+			return unmarshalSlice[uint64](value)
+		case "float32":
+			return unmarshalSlice[float32](value)
+		case "float64":
+			return unmarshalSlice[float64](value)
+		case "bool":
+			return unmarshalSlice[bool](value)
+		case "duration", "time.Duration":
+			// durations are written as strings like "1s", or as numbers of nanoseconds
+			var items []json.RawMessage
+			if value != "" {
+				if err := json.Unmarshal([]byte(value), &items); err != nil {
+					return nil, err
+				}
+			}
+			v := make([]time.Duration, len(items))
+			for i, item := range items {
+				var s string
+				var err error
+				if json.Unmarshal(item, &s) == nil {
+					v[i], err = time.ParseDuration(s)
+				} else {
+					err = json.Unmarshal(item, &v[i])
+				}
+				if err != nil {
+					return nil, err
+				}
+			}
+			return v, nil
+		}
+		return nil, fmt.Errorf("[]%s: %w", typ, errUnsupportedType)
 	}
 	var v interface{}
 	var err error
+	if value == "" && typ != "string" {
+		// "0" parses as the zero value of every other type, including false and 0s
+		value = "0"
+	}
 	switch typ {
 	case "string":
-		if slice {
-			v = []string{}
-			err = json.Unmarshal([]byte(value), &v)
-		} else {
-			v = value
-		}
+		v = value
 	case "int":
-		if slice {
-			v = []int{}
-			err = json.Unmarshal([]byte(value), &v)
-		} else {
-			v, err = strconv.Atoi(value)
-		}
+		v, err = strconv.Atoi(value)
 	case "int8":
-		if slice {
-			v = []int8{}
-			err = json.Unmarshal([]byte(value), &v)
-		} else {
-			n, e := strconv.ParseInt(value, 10, 8)
-			v = int8(n)
-			err = e
-		}
-
+		n, e := strconv.ParseInt(value, 10, 8)
+		v, err = int8(n), e
 	case "int16":
-		if slice {
-			v = []int16{}
-			err = json.Unmarshal([]byte(value), &v)
-		} else {
-			n, e := strconv.ParseInt(value, 10, 16)
-			v = int16(n)
-			err = e
-		}
-
+		n, e := strconv.ParseInt(value, 10, 16)
+		v, err = int16(n), e
 	case "int32":
-		if slice {
-			v = []int32{}
-			err = json.Unmarshal([]byte(value), &v)
-		} else {
-			n, e := strconv.ParseInt(value, 10, 32)
-			v = int32(n)
-			err = e
-		}
-
+		n, e := strconv.ParseInt(value, 10, 32)
+		v, err = int32(n), e
 	case "int64":
-		if slice {
-			v = []int64{}
-			err = json.Unmarshal([]byte(value), &v)
-		} else {
-			v, err = strconv.ParseInt(value, 10, 64)
-		}
-
+		v, err = strconv.ParseInt(value, 10, 64)
 	case "uint":
-		if slice {
-			v = []uint{}
-			err = json.Unmarshal([]byte(value), &v)
-		} else {
-			n, e := strconv.ParseUint(value, 10, 32)
-			v = uint(n)
-			err = e
-		}
-
+		n, e := strconv.ParseUint(value, 10, 0)
+		v, err = uint(n), e
 	case "uint8":
-		if slice {
-			v = []uint8{}
-			err = json.Unmarshal([]byte(value), &v)
-		} else {
-			n, e := strconv.ParseUint(value, 10, 8)
-			v = uint8(n)
-			err = e
-		}
-
+		n, e := strconv.ParseUint(value, 10, 8)
+		v, err = uint8(n), e
 	case "uint16":
-		if slice {
-			v = []uint16{}
-			err = json.Unmarshal([]byte(value), &v)
-		} else {
-			n, e := strconv.ParseUint(value, 10, 16)
-			v = uint16(n)
-			err = e
-		}
-
+		n, e := strconv.ParseUint(value, 10, 16)
+		v, err = uint16(n), e
 	case "uint32":
-		if slice {
-			v = []uint32{}
-			err = json.Unmarshal([]byte(value), &v)
-		} else {
-			n, e := strconv.ParseUint(value, 10, 32)
-			v = uint32(n)
-			err = e
-		}
-
+		n, e := strconv.ParseUint(value, 10, 32)
+		v, err = uint32(n), e
 	case "uint64":
-		if slice {
-			v = []uint64{}
-			err = json.Unmarshal([]byte(value), &v)
-		} else {
-			v, err = strconv.ParseUint(value, 10, 64)
-		}
-
+		v, err = strconv.ParseUint(value, 10, 64)
 	case "float32":
-		if slice {
-			v = []float32{}
-			err = json.Unmarshal([]byte(value), &v)
-		} else {
-			n, e := strconv.ParseFloat(value, 32)
-			v = float32(n)
-			err = e
-		}
-
+		n, e := strconv.ParseFloat(value, 32)
+		v, err = float32(n), e
 	case "float64":
-		if slice {
-			v = []float64{}
-			err = json.Unmarshal([]byte(value), &v)
-		} else {
-			v, err = strconv.ParseFloat(value, 64)
-		}
-
+		v, err = strconv.ParseFloat(value, 64)
 	case "bool":
-		if slice {
-			v = []bool{}
-			err = json.Unmarshal([]byte(value), &v)
-		} else {
-			v, err = strconv.ParseBool(value)
-		}
-
+		v, err = strconv.ParseBool(value)
 	case "duration", "time.Duration":
-		if slice {
-			v = []time.Duration{}
-			err = json.Unmarshal([]byte(value), &v)
-		} else {
-			v, err = time.ParseDuration(value)
-		}
+		v, err = time.ParseDuration(value)
 	default:
-		err = errors.New(typ + ": unsupported type")
+		return nil, fmt.Errorf("%s: %w", typ, errUnsupportedType)
+	}
+	if err != nil {
+		return nil, err
 	}
-	if err != nil && value != "" {
-		panic(fmt.Sprintf("flag tag %#v value %#v invalid: %v", name, value, err))
+	return v, nil
+}
+
+func unmarshalSlice[T any](value string) (interface{}, error) {
+	v := []T{}
+	if value == "" {
+		return v, nil
+	}
+	if err := json.Unmarshal([]byte(value), &v); err != nil {
+		return nil, err
 	}
-	return v
+	return v, nil
 }
@@ -0,0 +1,303 @@
+package bindflags
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidationError reports a field whose value does not satisfy a rule of its tag
+type ValidationError struct {
+	// Flag is the dotted flag name of the field, or the name of a positional argument
+	Flag string
+	// Rule is the failed rule as written in the tag, such as max:65535
+	Rule string
+	// Value is the value of the field
+	Value any
+	Err   error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("flag %s: rule %s: %v", e.Flag, e.Rule, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors is returned by Validate when one or more fields are invalid
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	s := make([]string, len(e))
+	for i, err := range e {
+		s[i] = err.Error()
+	}
+	return strings.Join(s, "; ")
+}
+
+func (e ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Is reports whether one of the errors matches target, for errors.Is before Go 1.20, which does not call Unwrap() []error
+func (e ValidationErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the errors that matches target, for errors.As before Go 1.20
+func (e ValidationErrors) As(target any) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the validation rules declared in the tags of the struct a, call it after FlagSet.Parse.
+// group must be the same as the one given to BindPFlags or BindFlags, so that errors name the bound flags.
+// Both read the same rules from a tag, since a rule is given with its key or, for required, as a bare word. Rules:
+//
+//	required          the value is not the zero value, a slice or map is not empty
+//	min:1 max:65535   bounds of a number or duration, or of the length of a string, slice or map
+//	oneof:a|b|c       the value, or every element of a slice, is one of the listed values
+//	pattern:^[a-z]+$  the value, or every element of a slice, matches the regular expression
+//
+// Validate 在 FlagSet.Parse 之后检查结构体 a 的 tag 中声明的校验规则，如 required、min:1、max:65535、oneof:a|b、pattern:^[a-z]+$
+func Validate(a any, group ...string) error {
+	rv := reflect.ValueOf(a)
+	if rv.Kind() != reflect.Ptr {
+		return errors.New("a must be a pointer")
+	}
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		return errors.New("a must be a struct")
+	}
+	var errs ValidationErrors
+	if err := validateStruct(rv, group, &errs); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validateStruct(rv reflect.Value, group []string, errs *ValidationErrors) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		ft := rt.Field(i)
+		if !ft.IsExported() {
+			continue
+		}
+		tag := ft.Tag.Get(TagName)
+		if tag == "-" || isCommandField(ft) {
+			continue
+		}
+		fv := rv.Field(i)
+		isNil := false
+		if fv.Kind() == reflect.Ptr {
+			if isNil = fv.IsNil(); isNil {
+				fv = reflect.Zero(fv.Type().Elem())
+			} else {
+				fv = fv.Elem()
+			}
+		}
+		// required means a pointer is not nil, even when it points to the zero value, like --compress=false,
+		// and an Optional is set, even to the zero value, like --port 0
+		missing := isNil
+		if set, ok := optionalIsSet(fv); ok && !isNil {
+			missing = !set
+		}
+		if elem, ok := optionalElem(fv); ok {
+			fv = elem
+		} else if rv.Field(i).Kind() != reflect.Ptr {
+			missing = isZeroValue(fv)
+		}
+		if isArgField(ft) {
+			tag = ft.Tag.Get(ArgTagName)
+			kv, err := scanTag(tag, argNames)
+			if err != nil {
+				return &TagError{Struct: rt, Field: ft.Name, Tag: tag, Err: err}
+			}
+			if kv["name"] == "" {
+				kv["name"] = "arg " + kv["index"]
+			}
+			checkRules(fv, kv["name"], kv, missing, errs)
+			continue
+		}
+		var kv map[string]string
+		if tag != "" {
+			var err error
+			if kv, err = scanTag(tag, pFlagNames); err != nil {
+				return &TagError{Struct: rt, Field: ft.Name, Tag: tag, Err: err}
+			}
+		} else if !isGroupType(fv.Type()) {
+			continue
+		}
+		name := kv["name"]
+		if isGroupType(fv.Type()) {
+			sub := group
+			if name != "" {
+				sub = append(append([]string{}, group...), name)
+			}
+			if err := validateStruct(fv, sub, errs); err != nil {
+				return err
+			}
+			continue
+		}
+		if required, ok := kv["required"]; isNil {
+			// a nil pointer has no value to check against the rules other than required
+			kv = make(map[string]string)
+			if ok {
+				kv["required"] = required
+			}
+		}
+		checkRules(fv, strings.Join(append(append([]string{}, group...), name), "."), kv, missing, errs)
+	}
+	return nil
+}
+
+// checkRules checks the value v of the flag or argument name against the rules found in the tag keys kv,
+// missing tells whether the value is absent for the required rule
+func checkRules(v reflect.Value, name string, kv map[string]string, missing bool, errs *ValidationErrors) {
+	for _, rule := range ruleNames {
+		arg, ok := kv[rule]
+		if !ok {
+			continue
+		}
+		if err := checkRule(v, rule, arg, missing); err != nil {
+			text := rule
+			if rule != "required" {
+				text += ":" + arg
+			}
+			*errs = append(*errs, &ValidationError{Flag: name, Rule: text, Value: v.Interface(), Err: err})
+		}
+	}
+}
+
+func checkRule(v reflect.Value, rule, arg string, missing bool) error {
+	switch rule {
+	case "required":
+		if required, err := strconv.ParseBool(arg); err != nil || !required {
+			return err
+		}
+		if missing {
+			return errors.New("value is required")
+		}
+	case "min", "max":
+		n, err := compareRule(v, arg)
+		if err != nil {
+			return err
+		}
+		if rule == "min" && n < 0 {
+			return fmt.Errorf("%v is less than %s", v.Interface(), arg)
+		}
+		if rule == "max" && n > 0 {
+			return fmt.Errorf("%v is greater than %s", v.Interface(), arg)
+		}
+	case "oneof":
+		options := strings.Split(arg, "|")
+		return eachElem(v, func(s string) error {
+			for _, option := range options {
+				if s == option {
+					return nil
+				}
+			}
+			return fmt.Errorf("%q is not one of %s", s, strings.Join(options, ", "))
+		})
+	case "pattern":
+		re, err := regexp.Compile(arg)
+		if err != nil {
+			return err
+		}
+		return eachElem(v, func(s string) error {
+			if !re.MatchString(s) {
+				return fmt.Errorf("%q does not match %s", s, arg)
+			}
+			return nil
+		})
+	}
+	return nil
+}
+
+// isZeroValue reports whether v is the zero value, or an empty slice or map
+func isZeroValue(v reflect.Value) bool {
+	return v.IsZero() || ((v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() == 0)
+}
+
+// compareRule compares the number, duration or length v with the bound arg, returning -1, 0 or 1
+func compareRule(v reflect.Value, arg string) (int, error) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var bound int64
+		var err error
+		if v.Type() == reflect.TypeOf(time.Duration(0)) {
+			var d time.Duration
+			d, err = time.ParseDuration(arg)
+			bound = int64(d)
+		} else {
+			bound, err = strconv.ParseInt(arg, 10, 64)
+		}
+		if err != nil {
+			return 0, err
+		}
+		return compare(v.Int() < bound, v.Int() > bound), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		bound, err := strconv.ParseUint(arg, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return compare(v.Uint() < bound, v.Uint() > bound), nil
+	case reflect.Float32, reflect.Float64:
+		bound, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return 0, err
+		}
+		return compare(v.Float() < bound, v.Float() > bound), nil
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		bound, err := strconv.Atoi(arg)
+		if err != nil {
+			return 0, err
+		}
+		return compare(v.Len() < bound, v.Len() > bound), nil
+	}
+	return 0, fmt.Errorf("unsupported type %s", v.Type())
+}
+
+func compare(less, greater bool) int {
+	if less {
+		return -1
+	}
+	if greater {
+		return 1
+	}
+	return 0
+}
+
+// eachElem calls check with the text of v, or of every element of v when it is a slice
+func eachElem(v reflect.Value, check func(s string) error) error {
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return check(fmt.Sprint(v.Interface()))
+	}
+	for i := 0; i < v.Len(); i++ {
+		if err := check(fmt.Sprint(v.Index(i).Interface())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
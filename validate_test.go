@@ -0,0 +1,87 @@
+package bindflags
+
+import (
+	"errors"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+type validateConfig struct {
+	Name  string   `flag:"name:name;required"`
+	Level string   `flag:"name:level;value:info;oneof:debug|info|warn"`
+	Tags  []string `flag:"name:tags;max:2;pattern:'^[a-z;]+$'"`
+	Net   struct {
+		Port    int           `flag:"name:port;value:80;min:1;max:65535"`
+		Timeout time.Duration `flag:"name:timeout;min:1s"`
+	} `flag:"net"`
+}
+
+func TestValidate(t *testing.T) {
+	c := new(validateConfig)
+	c.Name = "svc"
+	c.Level = "info"
+	c.Tags = []string{"a", "b;c"}
+	c.Net.Port = 80
+	c.Net.Timeout = time.Second
+	if err := Validate(c); err != nil {
+		t.Fatal(err)
+	}
+
+	c.Name = ""
+	c.Level = "trace"
+	c.Tags = []string{"a", "B", "c"}
+	c.Net.Port = 70000
+	c.Net.Timeout = time.Millisecond
+	err := Validate(c)
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+	want := []string{"name required", "level oneof:debug|info|warn", "tags max:2", "tags pattern:^[a-z;]+$", "net.port max:65535", "net.timeout min:1s"}
+	if len(errs) != len(want) {
+		t.Fatalf("got %d errors, want %d: %v", len(errs), len(want), err)
+	}
+	for i, e := range errs {
+		if e.Flag+" "+e.Rule != want[i] {
+			t.Errorf("error %d: got %q, want %q", i, e.Flag+" "+e.Rule, want[i])
+		}
+	}
+	var ve *ValidationError
+	if !errors.As(err, &ve) || ve.Flag != "name" {
+		t.Fatalf("errors.As should find the first ValidationError, got %v", ve)
+	}
+	// the methods errors.As and errors.Is use before Go 1.20
+	if ve = nil; !errs.As(&ve) || ve.Flag != "name" || !errs.Is(errs[1]) || errs.Is(errors.New("other")) {
+		t.Fatalf("As and Is should look at every error, got %v", ve)
+	}
+}
+
+func TestValidateAfterParse(t *testing.T) {
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	c := new(validateConfig)
+	MustBindPFlags(f, c)
+	if err := f.Parse([]string{"--name", "svc", "--net.port", "0"}); err != nil {
+		t.Fatal(err)
+	}
+	err := Validate(c)
+	var ve *ValidationError
+	if !errors.As(err, &ve) || ve.Flag != "net.port" || ve.Rule != "min:1" {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestValidateBindFlags(t *testing.T) {
+	c := &struct {
+		A string `flag:"a;;usage;TEST_VALIDATE_A;required"`
+	}{}
+	if err := BindFlags(flag.NewFlagSet("test", flag.ContinueOnError), c); err != nil {
+		t.Fatal(err)
+	}
+	var errs ValidationErrors
+	if err := Validate(c); !errors.As(err, &errs) || len(errs) != 1 || errs[0].Flag != "a" || errs[0].Rule != "required" {
+		t.Fatalf("expected a required error, got %v", err)
+	}
+}
@@ -0,0 +1,612 @@
+package bindflags
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// customValue returns a pflag.Value for an Optional, a type given to RegisterType, or using the methods of the type of v:
+// pflag.Value, flag.Value, or encoding.TextUnmarshaler together with encoding.TextMarshaler; nil when v has none of them
+func customValue(v reflect.Value) pflag.Value {
+	if value, _ := newOptionalValue(v, new(PFlagTag)); value != nil {
+		return value
+	}
+	if value := registeredValue(v); value != nil {
+		return value
+	}
+	if value := netValue(v); value != nil {
+		return value
+	}
+	return methodValue(v)
+}
+
+// methodValue returns a pflag.Value using the methods of the type of v, nil when it has none of them
+func methodValue(v reflect.Value) pflag.Value {
+	p := v.Addr().Interface()
+	switch x := p.(type) {
+	case pflag.Value:
+		return x
+	case flag.Value:
+		return &goFlagValue{Value: x, typ: v.Type().Name()}
+	}
+	u, ok := p.(encoding.TextUnmarshaler)
+	if !ok {
+		return nil
+	}
+	if m, ok := p.(encoding.TextMarshaler); ok {
+		return &textValue{u: u, m: m, typ: v.Type().Name()}
+	}
+	return nil
+}
+
+// isMethodValue reports whether value was returned by methodValue for the field v
+func isMethodValue(value pflag.Value, v reflect.Value) bool {
+	switch value.(type) {
+	case *goFlagValue, *textValue:
+		return true
+	}
+	return v.CanAddr() && value == v.Addr().Interface()
+}
+
+// newValue returns the pflag.Value setting the field v. native is false for a type with its own parse function:
+// a registered type, time.Time, or a type with the methods used by customValue other than net.IP.
+// errUnsupportedType is returned when v cannot be converted from text.
+func newValue(v reflect.Value, flagTag *PFlagTag) (value pflag.Value, native bool, err error) {
+	t := v.Type()
+	if value, err = newOptionalValue(v, flagTag); value != nil {
+		return value, false, err
+	}
+	if value = newTimeValue(v, flagTag.Layout); value != nil {
+		return value, false, nil
+	}
+	if value = registeredValue(v); value != nil {
+		return value, false, nil
+	}
+	if value = netValue(v); value != nil {
+		return value, true, nil
+	}
+	if value = methodValue(v); value != nil {
+		return value, t == ipType, nil
+	}
+	switch {
+	case isBytesType(t):
+		return &bytesValue{p: (*[]byte)(v.Addr().UnsafePointer()), encoding: flagTag.Encoding}, true, nil
+	case t.Kind() == reflect.Map:
+		if err = checkConvert(t.Key()); err == nil {
+			err = checkConvert(t.Elem())
+		}
+		return newMapValue(v), true, err
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return newSliceValue(v), true, checkConvert(t.Elem())
+	}
+	if t.Kind() == reflect.Bool {
+		return &boolValue{&scalarValue{value: v}}, true, checkConvert(t)
+	}
+	return &scalarValue{value: v}, true, checkConvert(t)
+}
+
+// checkConvert returns an error wrapping errUnsupportedType when convertTo cannot convert text to t
+func checkConvert(t reflect.Type) error {
+	if customValue(reflect.New(t).Elem()) != nil {
+		return nil
+	}
+	_, err := convertValue("", typeName(t))
+	return err
+}
+
+// setDefault sets the tag value s, the default, on the field v through value. A native value starts from the zero value,
+// any value does when s is empty except one given by the methods of its type, which may hold state of its own like the
+// values it accepts. A map or a slice is not marked as set, so that the first value on the command line replaces the default.
+func setDefault(value pflag.Value, v reflect.Value, s string, native bool) error {
+	if native || (s == "" && !isMethodValue(value, v)) {
+		v.Set(reflect.Zero(v.Type()))
+	}
+	switch x := value.(type) {
+	case *boolValue:
+		return setDefault(x.Value, v, s, native)
+	case *optionalValue:
+		return x.setDefault(s)
+	case *optionalSliceValue:
+		return x.setDefault(s)
+	case *mapValue:
+		err := x.Set(s)
+		x.changed = false
+		unsetOptionals(x.value)
+		return err
+	case *sliceValue:
+		if def, ok := x.unmarshalDefault(s); ok {
+			return x.replaceWith(def)
+		}
+		items, err := parseItems(s)
+		if err != nil {
+			return err
+		}
+		err = x.Replace(items)
+		unsetOptionals(x.value)
+		return err
+	}
+	if s == "" {
+		return nil
+	}
+	return value.Set(s)
+}
+
+// isPlainType reports whether a field of type t can be bound with the flag function of pflag or flag for its kind:
+// t is time.Duration, or has no methods and no value given by customValue
+func isPlainType(t reflect.Type) bool {
+	if t == durationType {
+		return true
+	}
+	return reflect.PointerTo(t).NumMethod() == 0 && customValue(reflect.New(t).Elem()) == nil
+}
+
+// isGroupType reports whether a field of type t is a group of flags rather than a single flag:
+// a struct without a value given by customValue
+func isGroupType(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && customValue(reflect.New(t).Elem()) == nil
+}
+
+// newTimeValue returns a timeValue for a time.Time field, unless time.Time is given to RegisterType; nil for the other types
+func newTimeValue(v reflect.Value, layout string) pflag.Value {
+	if v.Type() != timeType || lookupType(timeType) != nil {
+		return nil
+	}
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	return &timeValue{p: v.Addr().Interface().(*time.Time), layout: layout}
+}
+
+// timeValue is a pflag.Value for a time.Time written with layout.
+// RFC 3339 is accepted too, it is the format of the timestamps decoded from YAML and TOML config files.
+type timeValue struct {
+	p      *time.Time
+	layout string
+}
+
+func (v *timeValue) Set(s string) error {
+	t, err := time.Parse(v.layout, s)
+	if err != nil {
+		var e error
+		if t, e = time.Parse(time.RFC3339Nano, s); e != nil {
+			return err
+		}
+	}
+	*v.p = t
+	return nil
+}
+
+func (v *timeValue) String() string {
+	// the zero time is shown as no default
+	if v.p == nil || v.p.IsZero() {
+		return ""
+	}
+	return v.p.Format(v.layout)
+}
+
+func (v *timeValue) Type() string {
+	return "time"
+}
+
+// goFlagValue adds the Type method of pflag.Value to a flag.Value
+type goFlagValue struct {
+	flag.Value
+	typ string
+}
+
+func (v *goFlagValue) String() string {
+	// the zero goFlagValue is created by flag.PrintDefaults to find the zero value
+	if v.Value == nil {
+		return ""
+	}
+	return v.Value.String()
+}
+
+func (v *goFlagValue) Type() string {
+	return v.typ
+}
+
+// textValue is a pflag.Value for a type implementing encoding.TextUnmarshaler and encoding.TextMarshaler
+type textValue struct {
+	u   encoding.TextUnmarshaler
+	m   encoding.TextMarshaler
+	typ string
+}
+
+func (v *textValue) Set(s string) error {
+	return v.u.UnmarshalText([]byte(s))
+}
+
+func (v *textValue) String() string {
+	if v.m == nil {
+		return ""
+	}
+	b, err := v.m.MarshalText()
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func (v *textValue) Type() string {
+	return v.typ
+}
+
+// typeName returns the name convertValue uses for the type t, or the Type of the value of a registered or custom type
+func typeName(t reflect.Type) string {
+	if lookupType(t) != nil {
+		return registeredName(t)
+	}
+	if t == durationType {
+		return "duration"
+	}
+	if value := customValue(reflect.New(t).Elem()); value != nil {
+		return value.Type()
+	}
+	return t.Kind().String()
+}
+
+// convertTo converts s to a value of the type t with the value given by customValue, or with convertValue
+func convertTo(t reflect.Type, s string) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+	if value := customValue(v); value != nil {
+		if err := value.Set(s); err != nil {
+			return reflect.Value{}, err
+		}
+		return v, nil
+	}
+	x, err := convertValue(s, typeName(t))
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return reflect.ValueOf(x).Convert(t), nil
+}
+
+// mapValue is a pflag.Value for a map field, written as k=v,k2=v2 on the command line.
+// The first Set replaces the map, the next ones add to it.
+type mapValue struct {
+	value   reflect.Value
+	changed bool
+}
+
+func newMapValue(value reflect.Value) *mapValue {
+	return &mapValue{value: value}
+}
+
+func (m *mapValue) Set(s string) error {
+	v, err := parseMap(m.value.Type(), s)
+	if err != nil {
+		return err
+	}
+	if !m.changed || m.value.IsNil() {
+		m.value.Set(v)
+	} else {
+		iter := v.MapRange()
+		for iter.Next() {
+			m.value.SetMapIndex(iter.Key(), iter.Value())
+		}
+	}
+	m.changed = true
+	return nil
+}
+
+func (m *mapValue) replace(s string) error {
+	err := m.Set(s)
+	m.changed = false
+	return err
+}
+
+func (m *mapValue) Type() string {
+	name := typeName(m.value.Type().Elem())
+	return typeName(m.value.Type().Key()) + "To" + strings.ToUpper(name[:1]) + name[1:]
+}
+
+func (m *mapValue) String() string {
+	// an empty map is written as the empty string, which pflag takes for a zero default and does not show
+	if !m.value.IsValid() || m.value.Len() == 0 {
+		return ""
+	}
+	return "[" + formatMap(m.value) + "]"
+}
+
+// sliceValue is a pflag.SliceValue for a slice or an array of any type convertTo converts, written as a,b,c
+// or as a JSON array. The first Set replaces the content, the next ones append to it. An array is filled from
+// its first element and the rest is zeroed, more elements than its length is an error.
+type sliceValue struct {
+	value   reflect.Value
+	changed bool
+	// n is the number of elements set in an array
+	n int
+}
+
+func newSliceValue(value reflect.Value) *sliceValue {
+	return &sliceValue{value: value}
+}
+
+func (v *sliceValue) Set(s string) error {
+	items, err := parseItems(s)
+	if err != nil {
+		return err
+	}
+	if isJSONArray(s) {
+		// a JSON array replaces the content, as the tag value does, and the next values are appended to it
+		v.changed = true
+		return v.Replace(items)
+	}
+	if !v.changed {
+		v.changed = true
+		return v.Replace(items)
+	}
+	for _, item := range items {
+		if err = v.Append(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *sliceValue) Append(s string) error {
+	elem, err := convertTo(v.value.Type().Elem(), s)
+	if err != nil {
+		return err
+	}
+	if v.value.Kind() == reflect.Array {
+		if v.n >= v.value.Len() {
+			return fmt.Errorf("%s holds %d values", v.value.Type(), v.value.Len())
+		}
+		v.value.Index(v.n).Set(elem)
+		v.n++
+		return nil
+	}
+	v.value.Set(reflect.Append(v.value, elem))
+	return nil
+}
+
+func (v *sliceValue) Replace(items []string) error {
+	elemType := v.value.Type().Elem()
+	x := reflect.MakeSlice(reflect.SliceOf(elemType), len(items), len(items))
+	for i, item := range items {
+		elem, err := convertTo(elemType, item)
+		if err != nil {
+			return err
+		}
+		x.Index(i).Set(elem)
+	}
+	return v.replaceWith(x)
+}
+
+// replaceWith replaces the content with the elements of the slice x, converted to the element type
+func (v *sliceValue) replaceWith(x reflect.Value) error {
+	t := v.value.Type()
+	var s reflect.Value
+	if t.Kind() == reflect.Array {
+		if x.Len() > t.Len() {
+			return fmt.Errorf("%s holds %d values, %d given", t, t.Len(), x.Len())
+		}
+		s = reflect.New(t).Elem()
+	} else {
+		s = reflect.MakeSlice(t, x.Len(), x.Len())
+	}
+	for i := 0; i < x.Len(); i++ {
+		s.Index(i).Set(x.Index(i).Convert(t.Elem()))
+	}
+	v.value.Set(s)
+	v.n = x.Len()
+	return nil
+}
+
+// unmarshalDefault reads a JSON array tag value with convertValue, which takes a number in a []time.Duration
+// as nanoseconds; ok is false when s is not a JSON array or the element type has a value of its own
+func (v *sliceValue) unmarshalDefault(s string) (x reflect.Value, ok bool) {
+	elemType := v.value.Type().Elem()
+	if !isJSONArray(s) || customValue(reflect.New(elemType).Elem()) != nil {
+		return reflect.Value{}, false
+	}
+	def, err := convertValue(s, typeName(elemType), true)
+	if err != nil {
+		return reflect.Value{}, false
+	}
+	x = reflect.ValueOf(def)
+	return x, x.Kind() == reflect.Slice && x.Type().Elem().ConvertibleTo(elemType)
+}
+
+func (v *sliceValue) GetSlice() []string {
+	if !v.value.IsValid() {
+		return nil
+	}
+	items := make([]string, v.value.Len())
+	for i := range items {
+		items[i] = formatValue(v.value.Index(i))
+	}
+	return items
+}
+
+func (v *sliceValue) String() string {
+	// an empty slice or a zero array is written as the empty string, which pflag takes for a zero default and does not show
+	if !v.value.IsValid() || v.value.Len() == 0 || (v.value.Kind() == reflect.Array && v.value.IsZero()) {
+		return ""
+	}
+	return "[" + writeAsCSV(v.GetSlice()) + "]"
+}
+
+func (v *sliceValue) Type() string {
+	return typeName(v.value.Type().Elem()) + "Slice"
+}
+
+// scalarValue is a pflag.Value for a string, bool, number or duration field
+type scalarValue struct {
+	value reflect.Value
+}
+
+func (v *scalarValue) Set(s string) error {
+	x, err := convertTo(v.value.Type(), s)
+	if err != nil {
+		return err
+	}
+	v.value.Set(x)
+	return nil
+}
+
+func (v *scalarValue) String() string {
+	// the zero scalarValue is created by flag.PrintDefaults to find the zero value
+	if !v.value.IsValid() {
+		return "0"
+	}
+	return formatValue(v.value)
+}
+
+func (v *scalarValue) Type() string {
+	return typeName(v.value.Type())
+}
+
+// boolFlag is implemented by the values of the flags that can be given without a value, like the bool flags of the flag package
+type boolFlag interface {
+	IsBoolFlag() bool
+}
+
+// boolValue adds IsBoolFlag to the value of a bool. Only bool values have the method:
+// pflag takes a value having it for a bool flag, and shows its default unless it is false.
+type boolValue struct {
+	pflag.Value
+}
+
+func (v *boolValue) String() string {
+	// the zero boolValue is created by flag.PrintDefaults to find the zero value
+	if v == nil || v.Value == nil {
+		return "false"
+	}
+	return v.Value.String()
+}
+
+func (v *boolValue) IsBoolFlag() bool {
+	return true
+}
+
+func (v *boolValue) replace(s string) error {
+	if r, ok := v.Value.(replacer); ok {
+		return r.replace(s)
+	}
+	return v.Set(s)
+}
+
+// parseItems splits s, a JSON array or a list of values separated by commas
+func parseItems(s string) ([]string, error) {
+	if isJSONArray(s) {
+		return unmarshalItems(s)
+	}
+	return readAsCSV(s)
+}
+
+// isJSONArray reports whether s is a JSON array, a value such as [::1]:80 starting with [ is read as a,b,c
+func isJSONArray(s string) bool {
+	var raw []json.RawMessage
+	return strings.HasPrefix(strings.TrimSpace(s), "[") && json.Unmarshal([]byte(s), &raw) == nil
+}
+
+// unmarshalItems reads a JSON array, an element that is not a string is kept as its JSON text
+func unmarshalItems(s string) ([]string, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal([]byte(s), &raw); err != nil {
+		return nil, fmt.Errorf("invalid JSON array %q: %v", s, err)
+	}
+	items := make([]string, len(raw))
+	for i, item := range raw {
+		if json.Unmarshal(item, &items[i]) != nil {
+			items[i] = string(item)
+		}
+	}
+	return items, nil
+}
+
+// parseMap parses s into a map of type t, s is either a JSON object or a list of k=v pairs separated by commas
+func parseMap(t reflect.Type, s string) (reflect.Value, error) {
+	m := reflect.MakeMap(t)
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return m, nil
+	}
+	pairs := make(map[string]string)
+	if strings.HasPrefix(s, "{") {
+		var raw map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(s), &raw); err != nil {
+			return reflect.Value{}, err
+		}
+		for k, v := range raw {
+			var str string
+			if json.Unmarshal(v, &str) == nil {
+				pairs[k] = str
+			} else {
+				pairs[k] = string(v)
+			}
+		}
+	} else {
+		items, err := readAsCSV(s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		for _, item := range items {
+			k, v, ok := strings.Cut(item, "=")
+			if !ok {
+				return reflect.Value{}, fmt.Errorf("%q must be formatted as key=value", item)
+			}
+			pairs[k] = v
+		}
+	}
+	for k, v := range pairs {
+		key, err := convertTo(t.Key(), k)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		elem, err := convertTo(t.Elem(), v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		m.SetMapIndex(key, elem)
+	}
+	return m, nil
+}
+
+// formatMap writes the map v as k=v pairs sorted by key and separated by commas
+func formatMap(v reflect.Value) string {
+	items := make([]string, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		items = append(items, formatValue(iter.Key())+"="+formatValue(iter.Value()))
+	}
+	sort.Strings(items)
+	return writeAsCSV(items)
+}
+
+// formatValue writes v with the function given to RegisterType for its type, or with fmt; an Optional is written as its value
+func formatValue(v reflect.Value) string {
+	if conv := lookupType(v.Type()); conv != nil {
+		return conv.format(v)
+	}
+	if elem, ok := optionalElem(v); ok {
+		return formatValue(elem)
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+func writeAsCSV(items []string) string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write(items)
+	w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,281 @@
+package bindflags
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+type mapConfig struct {
+	Labels   map[string]string        `flag:"name:labels;shorthand:l;value:{\"app\":\"web\"}"`
+	Limits   map[string]int           `flag:"name:limits;value:cpu=2,mem=512"`
+	Gates    map[string]bool          `flag:"name:feature-gates;value:A=true"`
+	Timeouts map[string]time.Duration `flag:"name:timeouts;value:{\"read\":\"1s\"}"`
+	Weights  map[string]float64       `flag:"name:weights"`
+}
+
+func TestBindPFlagsMap(t *testing.T) {
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	c := new(mapConfig)
+	MustBindPFlags(f, c)
+	if c.Labels["app"] != "web" || c.Limits["mem"] != 512 || !c.Gates["A"] || c.Timeouts["read"] != time.Second || c.Weights == nil {
+		t.Fatalf("unexpected defaults: %#v", c)
+	}
+	err := f.Parse([]string{"-l", "env=prod", "--feature-gates=A=false,B=true", "--feature-gates", "C=true", "--timeouts", "write=2m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Labels) != 1 || c.Labels["env"] != "prod" {
+		t.Fatalf("unexpected labels %v", c.Labels)
+	}
+	if len(c.Gates) != 3 || c.Gates["A"] || !c.Gates["B"] || !c.Gates["C"] {
+		t.Fatalf("unexpected gates %v", c.Gates)
+	}
+	if len(c.Timeouts) != 1 || c.Timeouts["write"] != 2*time.Minute {
+		t.Fatalf("unexpected timeouts %v", c.Timeouts)
+	}
+	if s := f.Lookup("feature-gates").Value.String(); s != "[A=false,B=true,C=true]" {
+		t.Fatalf("unexpected string %q", s)
+	}
+	if err = f.Set("weights", "a=x"); err == nil {
+		t.Fatal("expected an invalid value error")
+	}
+}
+
+func TestBindFlagsMap(t *testing.T) {
+	f := flag.NewFlagSet("test", flag.ContinueOnError)
+	c := &struct {
+		Gates map[string]bool `flag:"name:feature-gates;value:A=true"`
+	}{}
+	MustBindFlags(f, c)
+	if err := f.Parse([]string{"-feature-gates", "B=true"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Gates) != 1 || !c.Gates["B"] {
+		t.Fatalf("unexpected gates %v", c.Gates)
+	}
+}
+
+func TestLoadConfigMap(t *testing.T) {
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	c := new(mapConfig)
+	b := new(Binder)
+	if err := b.BindPFlags(f, c); err != nil {
+		t.Fatal(err)
+	}
+	doc := `{"labels":{"team":"core","note":"a,b"},"feature-gates":{"X":true}}`
+	if err := b.LoadConfig(strings.NewReader(doc)); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Labels) != 2 || c.Labels["note"] != "a,b" || len(c.Gates) != 1 || !c.Gates["X"] {
+		t.Fatalf("unexpected values: %#v", c)
+	}
+}
+
+// headers is a pflag.Value on a map, reporting the type of the map values of pflag, with canonical keys
+type headers map[string]string
+
+func (h *headers) Set(s string) error {
+	m, err := parseMap(reflect.TypeOf(map[string]string{}), s)
+	if err != nil {
+		return err
+	}
+	*h = make(headers)
+	for k, v := range m.Interface().(map[string]string) {
+		(*h)[http.CanonicalHeaderKey(k)] = v
+	}
+	return nil
+}
+
+func (h *headers) String() string { return fmt.Sprint(map[string]string(*h)) }
+
+func (h *headers) Type() string { return "stringToString" }
+
+func TestLoadConfigMapValue(t *testing.T) {
+	c := &struct {
+		Headers headers `flag:"name:headers"`
+	}{}
+	b := new(Binder)
+	if err := b.BindPFlags(pflag.NewFlagSet("test", pflag.ContinueOnError), c); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.LoadConfig(strings.NewReader(`{"headers":{"x-request-id":"1"}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Headers) != 1 || c.Headers["X-Request-Id"] != "1" {
+		t.Fatalf("the config should go through Set: %v", c.Headers)
+	}
+}
+
+// level is a pflag.Value accepting debug, info or error
+type level string
+
+func (l *level) Set(s string) error {
+	switch s {
+	case "debug", "info", "error":
+		*l = level(s)
+		return nil
+	}
+	return fmt.Errorf("invalid level %q", s)
+}
+
+func (l *level) String() string { return string(*l) }
+
+func (l *level) Type() string { return "level" }
+
+// enum is a pflag.Value holding the values it accepts
+type enum struct {
+	Allowed []string
+	Value   string
+}
+
+func (e *enum) Set(s string) error {
+	for _, a := range e.Allowed {
+		if s == a {
+			e.Value = s
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %v", e.Allowed)
+}
+
+func (e *enum) String() string { return e.Value }
+
+func (e *enum) Type() string { return "enum" }
+
+func TestBindEnum(t *testing.T) {
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	c := &struct {
+		Mode enum `flag:"name:mode"`
+	}{Mode: enum{Allowed: []string{"a", "b"}}}
+	b := new(Binder)
+	if err := b.BindPFlags(f, c); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Parse([]string{"--mode", "b"}); err != nil || c.Mode.Value != "b" {
+		t.Fatalf("unexpected mode %#v: %v", c.Mode, err)
+	}
+	if err := b.LoadConfig(strings.NewReader(`{"mode":"c"}`)); err != nil {
+		t.Fatal("the changed flag should not be set from config:", err)
+	}
+	c.Mode.Value = ""
+	f = pflag.NewFlagSet("test", pflag.ContinueOnError)
+	b = new(Binder)
+	if err := b.BindPFlags(f, c); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.LoadConfig(strings.NewReader(`{"mode":"a"}`)); err != nil || c.Mode.Value != "a" {
+		t.Fatalf("unexpected mode %#v: %v", c.Mode, err)
+	}
+}
+
+// mode is a flag.Value without the Type method of pflag.Value
+type mode struct {
+	name string
+}
+
+func (m *mode) Set(s string) error {
+	if s == "" {
+		return fmt.Errorf("empty mode")
+	}
+	m.name = s
+	return nil
+}
+
+func (m *mode) String() string {
+	if m == nil {
+		return ""
+	}
+	return m.name
+}
+
+type customConfig struct {
+	Level level      `flag:"name:level;value:info"`
+	Mode  mode       `flag:"name:mode;value:fast"`
+	Addr  netip.Addr `flag:"name:addr;shorthand:a;value:127.0.0.1"`
+	Big   *big.Int   `flag:"name:big;value:12345678901234567890"`
+}
+
+func TestBindPFlagsCustom(t *testing.T) {
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	c := new(customConfig)
+	MustBindPFlags(f, c)
+	if c.Level != "info" || c.Mode.name != "fast" || c.Addr.String() != "127.0.0.1" || c.Big.String() != "12345678901234567890" {
+		t.Fatalf("unexpected defaults: %#v", c)
+	}
+	if typ := f.Lookup("mode").Value.Type(); typ != "mode" {
+		t.Fatalf("unexpected type %q", typ)
+	}
+	err := f.Parse([]string{"--level", "debug", "--mode=slow", "-a", "::1", "--big", "-5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Level != "debug" || c.Mode.name != "slow" || c.Addr.String() != "::1" || c.Big.Int64() != -5 {
+		t.Fatalf("unexpected values: %#v", c)
+	}
+	if err = f.Set("level", "trace"); err == nil {
+		t.Fatal("expected an invalid value error")
+	}
+
+	var tagErr *TagError
+	bad := &struct {
+		Level level `flag:"name:level;value:trace"`
+	}{}
+	if err = BindPFlags(pflag.NewFlagSet("test", pflag.ContinueOnError), bad); !errors.As(err, &tagErr) {
+		t.Fatalf("expected a TagError, got %v", err)
+	}
+}
+
+func TestBindFlagsCustom(t *testing.T) {
+	f := flag.NewFlagSet("test", flag.ContinueOnError)
+	c := &struct {
+		Level level      `flag:"name:level;value:info"`
+		Mode  mode       `flag:"name:mode;value:fast"`
+		Addr  netip.Addr `flag:"name:addr"`
+	}{}
+	MustBindFlags(f, c)
+	if c.Level != "info" || c.Mode.name != "fast" || c.Addr.IsValid() {
+		t.Fatalf("unexpected defaults: %#v", c)
+	}
+	if err := f.Parse([]string{"-level", "error", "-mode", "slow", "-addr", "10.0.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+	if c.Level != "error" || c.Mode.name != "slow" || c.Addr.String() != "10.0.0.1" {
+		t.Fatalf("unexpected values: %#v", c)
+	}
+}
+
+func TestBindCustomSlice(t *testing.T) {
+	type config struct {
+		Levels []level `flag:"name:ls"`
+	}
+	f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	c := new(config)
+	MustBindPFlags(f, c)
+	if typ := f.Lookup("ls").Value.Type(); typ != "levelSlice" {
+		t.Fatalf("unexpected type %q", typ)
+	}
+	if err := f.Parse([]string{"--ls", "debug,error"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Levels) != 2 || c.Levels[1] != "error" {
+		t.Fatalf("unexpected values: %v", c.Levels)
+	}
+	if err := f.Set("ls", "bogus"); err == nil {
+		t.Fatal("expected an invalid level error")
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	MustBindFlags(fs, new(config))
+	if err := fs.Set("ls", "bogus"); err == nil {
+		t.Fatal("expected an invalid level error")
+	}
+}